@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"texas-holdem/internal/api"
@@ -15,6 +16,14 @@ func main() {
 		port = "8080"
 	}
 
+	if v := os.Getenv("SIM_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("invalid SIM_WORKERS %q", v)
+		}
+		api.SetSimulationWorkers(n)
+	}
+
 	mux := http.NewServeMux()
 	api.RegisterRoutes(mux)
 
@@ -3,7 +3,11 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"runtime"
+	"strings"
+	"time"
 
 	"texas-holdem/internal/poker"
 )
@@ -15,12 +19,35 @@ type ErrorResponse struct {
 type BestHandRequest struct {
 	Hole      []string `json:"hole"`
 	Community []string `json:"community"`
+	Variant   string   `json:"variant,omitempty"`
+	// Wild lists ranks whose cards are wild, e.g. ["2"] for deuces wild.
+	// The joker "XJ" is always wild. Only Hold'em supports wild cards.
+	Wild []string `json:"wild,omitempty"`
+	// Relative asks best-hand to rank the hand against every holding an
+	// opponent could have on the board. Only two-card games support it.
+	Relative bool `json:"relative,omitempty"`
+	// Dead lists cards known to be out of play, which no opponent holding
+	// in a relative ranking contains.
+	Dead []string `json:"dead,omitempty"`
 }
 
 type BestHandResponse struct {
-	BestHand []string `json:"bestHand"`
-	Category string   `json:"category"`
-	Tiebreak []int    `json:"tiebreak"`
+	BestHand    []string `json:"bestHand"`
+	Category    string   `json:"category"`
+	Tiebreak    []int    `json:"tiebreak"`
+	Strength    int      `json:"strength"`
+	Description string   `json:"description"`
+	// PlayedAs is set when wild cards play: PlayedAs[i] is the card that
+	// BestHand[i] stands for.
+	PlayedAs []string `json:"playedAs,omitempty"`
+	// Low is the best qualifying low in hi-lo variants, if there is one.
+	Low *BestHandResponse `json:"low,omitempty"`
+	// Percentile, Nuts and NutHands are set for relative requests:
+	// the share of opponent holdings beaten (ties count half), whether no
+	// holding beats the hand, and the holdings that make the best hand.
+	Percentile *float64   `json:"percentile,omitempty"`
+	Nuts       *bool      `json:"nuts,omitempty"`
+	NutHands   [][]string `json:"nutHands,omitempty"`
 }
 
 type HeadsUpRequest struct {
@@ -33,24 +60,242 @@ type HeadsUpResponse struct {
 	Hand2   BestHandResponse `json:"hand2"`
 	Winner  string           `json:"winner"`
 	Outcome string           `json:"outcome"`
+	// LowWinner is set in hi-lo variants: "hand1", "hand2", "tie" or "none".
+	LowWinner string `json:"lowWinner,omitempty"`
+}
+
+type ShowdownRequest struct {
+	// Hands lists the hole cards of 2 to 10 players sharing the board.
+	Hands     [][]string `json:"hands"`
+	Community []string   `json:"community"`
+	Variant   string     `json:"variant,omitempty"`
+}
+
+type ShowdownResponse struct {
+	Players []ShowdownPlayer `json:"players"`
+	// Order groups player indexes from the best high hand to the worst;
+	// players in one group split. Winners is its first group.
+	Order   [][]int `json:"order"`
+	Winners []int   `json:"winners"`
+	// LowOrder does the same for qualifying lows in hi-lo variants.
+	LowOrder [][]int `json:"lowOrder,omitempty"`
+}
+
+// ShowdownPlayer is a player's hand and finishing place, 1 for the best
+// hand; tied players share a place.
+type ShowdownPlayer struct {
+	BestHandResponse
+	Place int `json:"place"`
+}
+
+type PotsRequest struct {
+	// Players are listed in seat order.
+	Players   []PotSeat `json:"players"`
+	Community []string  `json:"community"`
+	Variant   string    `json:"variant,omitempty"`
+	// Button is the dealer's seat index; odd chips go to the winners
+	// closest to its left.
+	Button int `json:"button"`
+}
+
+// PotSeat is a player's hand and chips at showdown. Folded players may omit
+// their hole cards.
+type PotSeat struct {
+	Hole      []string `json:"hole,omitempty"`
+	Committed int64    `json:"committed"`
+	Folded    bool     `json:"folded,omitempty"`
+}
+
+type PotsResponse struct {
+	// Pots lists the main pot first, then each side pot.
+	Pots []PotResponse `json:"pots"`
+	// Payouts is what each seat collects.
+	Payouts []int64 `json:"payouts"`
+}
+
+type PotResponse struct {
+	Amount     int64 `json:"amount"`
+	Eligible   []int `json:"eligible"`
+	Winners    []int `json:"winners"`
+	LowWinners []int `json:"lowWinners,omitempty"`
 }
 
 type OddsRequest struct {
-	Hole        []string `json:"hole"`
-	Community   []string `json:"community"`
-	Players     int      `json:"players"`
-	Simulations int      `json:"simulations"`
+	Hole      []string `json:"hole"`
+	Community []string `json:"community"`
+	Players   int      `json:"players"`
+	Variant   string   `json:"variant,omitempty"`
+	SimulationRequest
+}
+
+// SimulationRequest holds the settings shared by every equity calculation.
+type SimulationRequest struct {
+	Simulations int `json:"simulations"`
+	// Method is "exact", "montecarlo" or "auto" (the default), which
+	// enumerates every deal when there are few enough of them.
+	Method string `json:"method,omitempty"`
+	// Precision, if set, stops a simulation once the 95% confidence
+	// interval is within ±Precision; Simulations is then an upper bound.
+	Precision float64 `json:"precision,omitempty"`
+	// Workers is how many goroutines share the simulation, up to the
+	// server-wide limit; 0 uses the limit.
+	Workers int `json:"workers,omitempty"`
+	// Seed makes the simulation reproducible; without it the server picks
+	// one and returns it.
+	Seed *int64 `json:"seed,omitempty"`
+	// TimeBudgetMs, if set, stops a simulation after that many
+	// milliseconds and returns the estimate reached so far. Without
+	// Simulations, the simulation runs until the budget is spent.
+	TimeBudgetMs int `json:"timeBudgetMs,omitempty"`
+	// Dead lists cards known to be out of play (folded, burned or flashed).
+	Dead []string `json:"dead,omitempty"`
 }
 
 type OddsResponse struct {
+	// WinProbability is the expected pot share, counting ties as
+	// fractional wins; the same as Equity.
 	WinProbability float64 `json:"winProbability"`
+	PlayerEquity
+	// Opponents is how each randomly dealt opponent fares, in seat order.
+	Opponents []PlayerEquity `json:"opponents"`
+	SimulationResponse
+}
+
+// PlayerEquity is how one player fares over the deals evaluated.
+type PlayerEquity struct {
+	Win    float64 `json:"win"`
+	Tie    float64 `json:"tie"`
+	Loss   float64 `json:"loss"`
+	Equity float64 `json:"equity"`
+	// StdErr and ConfidenceInterval (95%) measure the sampling error of
+	// Equity; both are exact for the "exact" method.
+	StdErr             float64    `json:"stdErr"`
+	ConfidenceInterval [2]float64 `json:"confidenceInterval"`
+	// Categories maps each hand category (e.g. "flush") to how often the
+	// player ends with it, and OpponentCategories does the same for the
+	// best opposing hand.
+	Categories         map[string]float64 `json:"categories"`
+	OpponentCategories map[string]float64 `json:"opponentCategories"`
+}
+
+// SimulationResponse describes how an equity calculation ran.
+type SimulationResponse struct {
+	// Simulations is the number of deals actually evaluated.
+	Simulations int `json:"simulations"`
+	// Method is the method actually used: "exact" or "montecarlo".
+	Method string `json:"method"`
+	// Seed reproduces a "montecarlo" result when sent back in the request.
+	Seed *int64 `json:"seed,omitempty"`
+	// TimedOut reports that the time budget ended the simulation early.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+type EquityRequest struct {
+	// Hands lists every player's hole cards; an empty hand is dealt at random.
+	Hands [][]string `json:"hands,omitempty"`
+	// Ranges lists every player's range instead, e.g. "QQ+, AKs".
+	Ranges    []string `json:"ranges,omitempty"`
+	Community []string `json:"community"`
+	Variant   string   `json:"variant,omitempty"`
+	SimulationRequest
+}
+
+type EquityResponse struct {
+	Players []HandEquity `json:"players"`
+	SimulationResponse
+}
+
+// HandEquity is PlayerEquity for a requested hand or range.
+type HandEquity struct {
+	Hand  []string `json:"hand,omitempty"`
+	Range string   `json:"range,omitempty"`
+	PlayerEquity
+}
+
+type OutsRequest struct {
+	Hole      []string `json:"hole"`
+	Community []string `json:"community"`
+	// Opponent is the opponent's known hand; Range is their range instead,
+	// e.g. "QQ+, AKs". Exactly one of them must be given.
+	Opponent []string `json:"opponent,omitempty"`
+	Range    string   `json:"range,omitempty"`
+	Variant  string   `json:"variant,omitempty"`
+	Dead     []string `json:"dead,omitempty"`
+}
+
+type OutsResponse struct {
+	Outs []OutCard `json:"outs"`
+	// Count is the number of outs, each weighted by the share of the range
+	// it beats.
+	Count float64 `json:"count"`
+	// ByCategory sums the outs by the hand category they make.
+	ByCategory map[string]float64 `json:"byCategory"`
+	Unseen     int                `json:"unseen"`
+	// TurnProbability is the chance the next card is an out (0 on the
+	// turn); RiverProbability is the chance of hitting one by the river.
+	TurnProbability  float64 `json:"turnProbability"`
+	RiverProbability float64 `json:"riverProbability"`
+}
+
+type OutCard struct {
+	Card     string  `json:"card"`
+	Category string  `json:"category"`
+	Weight   float64 `json:"weight"`
+}
+
+type DrawsRequest struct {
+	// Hole is optional; without it only the board is analyzed.
+	Hole      []string `json:"hole,omitempty"`
+	Community []string `json:"community"`
+}
+
+type DrawsResponse struct {
+	Board BoardTexture `json:"board"`
+	// Draws is set when hole cards are given.
+	Draws *HeldDraws `json:"draws,omitempty"`
+}
+
+type BoardTexture struct {
+	Monotone         bool `json:"monotone"`
+	TwoTone          bool `json:"twoTone"`
+	Rainbow          bool `json:"rainbow"`
+	Paired           bool `json:"paired"`
+	Trips            bool `json:"trips"`
+	Connected        bool `json:"connected"`
+	StraightPossible bool `json:"straightPossible"`
+	FlushPossible    bool `json:"flushPossible"`
+	Wet              bool `json:"wet"`
+}
+
+type HeldDraws struct {
+	FlushDraw        bool `json:"flushDraw"`
+	OpenEnded        bool `json:"openEnded"`
+	Gutshot          bool `json:"gutshot"`
+	StraightRanks    int  `json:"straightRanks"`
+	BackdoorFlush    bool `json:"backdoorFlush"`
+	BackdoorStraight bool `json:"backdoorStraight"`
+	Overcards        int  `json:"overcards"`
+}
+
+// simPool caps the simulation goroutines running across all requests.
+var simPool = poker.NewWorkerPool(runtime.GOMAXPROCS(0))
+
+// SetSimulationWorkers sets the server-wide cap on concurrent simulation
+// goroutines. It must be called before the server starts.
+func SetSimulationWorkers(n int) {
+	simPool = poker.NewWorkerPool(n)
 }
 
 func RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/healthz", healthHandler)
 	mux.HandleFunc("/api/v1/best-hand", bestHandHandler)
 	mux.HandleFunc("/api/v1/heads-up", headsUpHandler)
+	mux.HandleFunc("/api/v1/showdown", showdownHandler)
+	mux.HandleFunc("/api/v1/pots", potsHandler)
 	mux.HandleFunc("/api/v1/odds", oddsHandler)
+	mux.HandleFunc("/api/v1/equity", equityHandler)
+	mux.HandleFunc("/api/v1/outs", outsHandler)
+	mux.HandleFunc("/api/v1/draws", drawsHandler)
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -90,22 +335,26 @@ func headsUpHandler(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp1, err := computeBest(req.Hand1)
+	h1, err := evaluateRequest(req.Hand1)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp2, err := computeBest(req.Hand2)
+	h2, err := evaluateRequest(req.Hand2)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if h1.variant != h2.variant {
+		writeError(w, http.StatusBadRequest, "hands must use the same variant")
+		return
+	}
+	if err := checkSharedCards(req.Hand1, req.Hand2); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	h1Cards, _ := poker.ParseCards(append(req.Hand1.Hole, req.Hand1.Community...))
-	h2Cards, _ := poker.ParseCards(append(req.Hand2.Hole, req.Hand2.Community...))
-	h1Rank, _ := poker.Evaluate7(h1Cards)
-	h2Rank, _ := poker.Evaluate7(h2Cards)
-	cmp := poker.Compare(h1Rank, h2Rank)
+	cmp := poker.Compare(h1.high, h2.high)
 	winner := "tie"
 	outcome := "tie"
 	if cmp > 0 {
@@ -116,12 +365,164 @@ func headsUpHandler(w http.ResponseWriter, r *http.Request) {
 		outcome = "hand2 wins"
 	}
 
-	writeJSON(w, http.StatusOK, HeadsUpResponse{
-		Hand1:   resp1,
-		Hand2:   resp2,
+	resp := HeadsUpResponse{
+		Hand1:   h1.response(),
+		Hand2:   h2.response(),
 		Winner:  winner,
 		Outcome: outcome,
-	})
+	}
+	if h1.variant.HiLo() {
+		switch {
+		case !h1.hasLow && !h2.hasLow:
+			resp.LowWinner = "none"
+		case !h2.hasLow || (h1.hasLow && poker.Compare(h1.low, h2.low) > 0):
+			resp.LowWinner = "hand1"
+		case !h1.hasLow || poker.Compare(h1.low, h2.low) < 0:
+			resp.LowWinner = "hand2"
+		default:
+			resp.LowWinner = "tie"
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// rejects a hole card of one hand that is also in the other hand or on its
+// board; only board cards may be shared.
+func checkSharedCards(a, b BestHandRequest) error {
+	sets := make([]poker.CardSet, 4)
+	for i, list := range [][]string{a.Hole, a.Community, b.Hole, b.Community} {
+		cards, err := poker.ParseCards(list)
+		if err != nil {
+			return err
+		}
+		sets[i] = poker.NewCardSet(cards...)
+	}
+	clash := sets[0].Intersect(sets[2].Union(sets[3])).Union(sets[2].Intersect(sets[1]))
+	if clash != 0 {
+		return fmt.Errorf("card '%s' is used by both hands", clash.Cards()[0])
+	}
+	return nil
+}
+
+func showdownHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req ShowdownRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hands, community, err := parseHands(req.Hands, req.Community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := poker.RankShowdown(variant, hands, community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := ShowdownResponse{
+		Players:  make([]ShowdownPlayer, len(hands)),
+		Order:    res.Order,
+		Winners:  res.Order[0],
+		LowOrder: res.LowOrder,
+	}
+	for i := range hands {
+		hand := evaluatedHand{variant: variant, high: res.High[i]}
+		if res.HasLow != nil {
+			hand.low, hand.hasLow = res.Low[i], res.HasLow[i]
+		}
+		resp.Players[i].BestHandResponse = hand.response()
+	}
+	// a group's place counts the players ahead of it, like 1, 1, 3
+	place := 1
+	for _, group := range res.Order {
+		for _, p := range group {
+			resp.Players[p].Place = place
+		}
+		place += len(group)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func potsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req PotsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	seats := make([][]string, len(req.Players))
+	for i, p := range req.Players {
+		seats[i] = p.Hole
+	}
+	holes, board, err := parseHands(seats, req.Community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// pots are only paid out once every card is dealt
+	if err := poker.CheckShowdownBoard(variant, board); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	players := make([]poker.PotPlayer, len(req.Players))
+	for i, p := range req.Players {
+		players[i] = poker.PotPlayer{Committed: p.Committed, Folded: p.Folded}
+		if p.Folded {
+			continue
+		}
+		if err := poker.CheckShowdownHand(variant, holes[i]); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("player %d: %s", i+1, err))
+			return
+		}
+		if players[i].High, err = poker.Evaluate(variant, holes[i], board); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("player %d: %s", i+1, err))
+			return
+		}
+		if variant.HiLo() {
+			if players[i].Low, players[i].HasLow, err = poker.EvaluateLow(variant, holes[i], board); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("player %d: %s", i+1, err))
+				return
+			}
+		}
+	}
+
+	res, err := poker.DistributePots(players, req.Button)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := PotsResponse{Pots: make([]PotResponse, 0, len(res.Pots)), Payouts: res.Payouts}
+	for _, pot := range res.Pots {
+		resp.Pots = append(resp.Pots, PotResponse{
+			Amount:     pot.Amount,
+			Eligible:   pot.Eligible,
+			Winners:    pot.Winners,
+			LowWinners: pot.LowWinners,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func oddsHandler(w http.ResponseWriter, r *http.Request) {
@@ -135,52 +536,446 @@ func oddsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hole, err := poker.ParseCards(req.Hole)
+	holes, community, err := parseHands([][]string{req.Hole}, req.Community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hole := holes[0]
+
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	method, err := poker.ParseMethod(req.Method)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := req.options()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := poker.Equity(r.Context(), variant, method, hole, community, req.Players, opts)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	resp := OddsResponse{
+		WinProbability:     res[0].Equity,
+		PlayerEquity:       playerEquity(res[0]),
+		SimulationResponse: simulationResponse(res[0]),
+	}
+	for _, opp := range res[1:] {
+		resp.Opponents = append(resp.Opponents, playerEquity(opp))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func equityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req EquityRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(req.Ranges) > 0 {
+		rangeEquity(w, r, req)
+		return
+	}
+
+	hands, community, err := parseHands(req.Hands, req.Community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	method, err := poker.ParseMethod(req.Method)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts, err := req.options()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := poker.EquityHands(r.Context(), variant, method, hands, community, opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := EquityResponse{SimulationResponse: simulationResponse(res[0])}
+	for i, hand := range hands {
+		names := make([]string, 0, len(hand))
+		for _, c := range hand {
+			names = append(names, c.String())
+		}
+		resp.Players = append(resp.Players, HandEquity{Hand: names, PlayerEquity: playerEquity(res[i])})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// answers an equity request made with ranges rather than hands.
+func rangeEquity(w http.ResponseWriter, r *http.Request, req EquityRequest) {
+	if len(req.Hands) > 0 {
+		writeError(w, http.StatusBadRequest, "give either hands or ranges, not both")
+		return
+	}
+	ranges := make([]poker.Range, len(req.Ranges))
+	for i, spec := range req.Ranges {
+		rng, err := poker.ParseRange(spec)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ranges[i] = rng
+	}
 	community, err := poker.ParseCards(req.Community)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	method, err := poker.ParseMethod(req.Method)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	prob, err := poker.MonteCarlo(hole, community, req.Players, req.Simulations)
+	opts, err := req.options()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := poker.EquityRanges(r.Context(), variant, method, ranges, community, opts)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, OddsResponse{WinProbability: prob})
+	resp := EquityResponse{SimulationResponse: simulationResponse(res[0])}
+	for i, spec := range req.Ranges {
+		resp.Players = append(resp.Players, HandEquity{Range: spec, PlayerEquity: playerEquity(res[i])})
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
-func computeBest(req BestHandRequest) (BestHandResponse, error) {
-	if len(req.Hole) != 2 {
-		return BestHandResponse{}, errors.New("hole must have 2 cards")
+func outsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
-	if len(req.Community) != 5 {
-		return BestHandResponse{}, errors.New("community must have 5 cards")
+	var req OutsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	allCards, err := poker.ParseCards(append(req.Hole, req.Community...))
+	if (len(req.Opponent) > 0) == (req.Range != "") {
+		writeError(w, http.StatusBadRequest, "give either an opponent hand or a range")
+		return
+	}
+
+	holes, board, err := parseHands([][]string{req.Hole, req.Opponent}, req.Community)
 	if err != nil {
-		return BestHandResponse{}, err
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	if len(allCards) != 7 {
-		return BestHandResponse{}, errors.New("invalid total cards")
+	hole, opponent := holes[0], holes[1]
+
+	var opp poker.Range
+	if req.Range != "" {
+		if opp, err = poker.ParseRange(req.Range); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		if len(opponent) != 2 {
+			writeError(w, http.StatusBadRequest, "opponent must have 2 cards")
+			return
+		}
+		opp = poker.Range{{Cards: [2]poker.Card(opponent), Weight: 1}}
+	}
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	rank, err := poker.Evaluate7(allCards)
+	dead, err := poker.ParseCards(req.Dead)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := poker.Outs(variant, hole, board, opp, dead)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := OutsResponse{
+		Outs:             make([]OutCard, 0, len(res.Outs)),
+		Count:            res.Count,
+		ByCategory:       categoryFreqs(res.ByCategory),
+		Unseen:           res.Unseen,
+		TurnProbability:  res.TurnProbability,
+		RiverProbability: res.RiverProbability,
+	}
+	for _, out := range res.Outs {
+		resp.Outs = append(resp.Outs, OutCard{Card: out.Card.String(), Category: out.Category.String(), Weight: out.Weight})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func drawsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req DrawsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holes, board, err := parseHands([][]string{req.Hole}, req.Community)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hole := holes[0]
+
+	texture, err := poker.AnalyzeBoard(board)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := DrawsResponse{Board: BoardTexture(texture)}
+	if len(hole) > 0 {
+		draws, err := poker.AnalyzeDraws(hole, board)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		held := HeldDraws(draws)
+		resp.Draws = &held
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parses every hand and the community together, so that a card repeated
+// anywhere is rejected, and splits them back apart.
+func parseHands(hands [][]string, community []string) ([][]poker.Card, []poker.Card, error) {
+	var all []string
+	for _, hand := range hands {
+		all = append(all, hand...)
+	}
+	cards, err := poker.ParseCards(append(all, community...))
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed := make([][]poker.Card, len(hands))
+	for i, hand := range hands {
+		parsed[i], cards = cards[:len(hand):len(hand)], cards[len(hand):]
+	}
+	return parsed, cards, nil
+}
+
+// returns the simulation options for the request, sharing the server-wide pool.
+func (req SimulationRequest) options() (poker.MonteCarloOptions, error) {
+	dead, err := poker.ParseCards(req.Dead)
+	if err != nil {
+		return poker.MonteCarloOptions{}, err
+	}
+	return poker.MonteCarloOptions{
+		Simulations: req.Simulations,
+		Precision:   req.Precision,
+		Workers:     req.Workers,
+		Pool:        simPool,
+		Seed:        req.Seed,
+		TimeBudget:  time.Duration(req.TimeBudgetMs) * time.Millisecond,
+		Dead:        dead,
+	}, nil
+}
+
+func playerEquity(res poker.EquityResult) PlayerEquity {
+	return PlayerEquity{
+		Win:                res.Win,
+		Tie:                res.Tie,
+		Loss:               res.Loss,
+		Equity:             res.Equity,
+		StdErr:             res.StdErr,
+		ConfidenceInterval: [2]float64{res.CILow, res.CIHigh},
+		Categories:         categoryFreqs(res.Categories),
+		OpponentCategories: categoryFreqs(res.OpponentCategories),
+	}
+}
+
+func categoryFreqs(freqs map[poker.Category]float64) map[string]float64 {
+	out := make(map[string]float64, len(freqs))
+	for c, p := range freqs {
+		out[c.String()] = p
+	}
+	return out
+}
+
+func simulationResponse(res poker.EquityResult) SimulationResponse {
+	resp := SimulationResponse{
+		Simulations: res.Simulations,
+		Method:      res.Method.String(),
+		TimedOut:    res.TimedOut,
+	}
+	if res.Method == poker.MethodMonteCarlo {
+		resp.Seed = &res.Seed
+	}
+	return resp
+}
+
+func computeBest(req BestHandRequest) (BestHandResponse, error) {
+	hand, err := evaluateRequest(req)
 	if err != nil {
 		return BestHandResponse{}, err
 	}
+	dead, err := poker.ParseCards(req.Dead)
+	if err != nil {
+		return BestHandResponse{}, err
+	}
+	known := poker.NewCardSet(append(append([]poker.Card{}, hand.hole...), hand.board...)...)
+	for _, c := range dead {
+		if known.Contains(c) {
+			return BestHandResponse{}, fmt.Errorf("dead card '%s' is also in the hand or on the board", c)
+		}
+	}
+	resp := hand.response()
+	if req.Relative {
+		if hand.wild {
+			return BestHandResponse{}, errors.New("relative strength is not supported with wild cards")
+		}
+		rel, err := poker.EvaluateRelative(hand.variant, hand.hole, hand.board, dead)
+		if err != nil {
+			return BestHandResponse{}, err
+		}
+		resp.Percentile, resp.Nuts = &rel.Percentile, &rel.Nuts
+		for _, h := range rel.NutHands {
+			resp.NutHands = append(resp.NutHands, []string{h[0].String(), h[1].String()})
+		}
+	}
+	return resp, nil
+}
+
+// evaluatedHand is a parsed request scored under its variant.
+type evaluatedHand struct {
+	variant     poker.Variant
+	hole, board []poker.Card
+	// wild is set when wild cards were in play.
+	wild   bool
+	high   poker.HandRank
+	low    poker.HandRank
+	hasLow bool
+}
+
+// parses and evaluates a single hand under its requested variant.
+func evaluateRequest(req BestHandRequest) (evaluatedHand, error) {
+	variant, err := poker.ParseVariant(req.Variant)
+	if err != nil {
+		return evaluatedHand{}, err
+	}
+	// unlike the other endpoints, a single hand may hold the joker
+	allCards, err := poker.ParseWildCards(append(append([]string{}, req.Hole...), req.Community...))
+	if err != nil {
+		return evaluatedHand{}, err
+	}
+	hole, board := allCards[:len(req.Hole):len(req.Hole)], allCards[len(req.Hole):]
+	hand := evaluatedHand{variant: variant, hole: hole, board: board}
+	wild, err := parseWild(req.Wild)
+	if err != nil {
+		return evaluatedHand{}, err
+	}
+	if wild != 0 || poker.NewCardSet(allCards...).Contains(poker.Joker) {
+		if variant != poker.Holdem {
+			return evaluatedHand{}, fmt.Errorf("wild cards are not supported for %s", variant)
+		}
+		if len(hole) != 2 || len(board) < 3 || len(board) > 5 {
+			return evaluatedHand{}, errors.New("hole must have 2 cards and community 3, 4, or 5 cards")
+		}
+		hand.high, err = poker.EvaluateWild(allCards, wild)
+		if err != nil {
+			return evaluatedHand{}, err
+		}
+		hand.wild = true
+		return hand, nil
+	}
+	hand.high, err = poker.Evaluate(variant, hole, board)
+	if err != nil {
+		return evaluatedHand{}, err
+	}
+	if variant.HiLo() {
+		hand.low, hand.hasLow, err = poker.EvaluateLow(variant, hole, board)
+		if err != nil {
+			return evaluatedHand{}, err
+		}
+	}
+	return hand, nil
+}
+
+// parses wild rank names such as "2" or "J" into the cards they make wild.
+func parseWild(ranks []string) (poker.CardSet, error) {
+	var wild poker.CardSet
+	for _, r := range ranks {
+		r = strings.TrimSpace(strings.ToUpper(r))
+		if len(r) != 1 {
+			return 0, fmt.Errorf("invalid wild rank '%s'", r)
+		}
+		cards, err := poker.WildRank(r[0])
+		if err != nil {
+			return 0, err
+		}
+		wild = wild.Union(cards)
+	}
+	return wild, nil
+}
+
+func (h evaluatedHand) response() BestHandResponse {
+	resp := bestHandResponse(h.high)
+	if h.hasLow {
+		low := bestHandResponse(h.low)
+		resp.Low = &low
+	}
+	return resp
+}
+
+func bestHandResponse(rank poker.HandRank) BestHandResponse {
 	best := make([]string, 0, len(rank.Best5))
 	for _, c := range rank.Best5 {
 		best = append(best, c.String())
 	}
-	return BestHandResponse{
-		BestHand: best,
-		Category: rank.Name(),
-		Tiebreak: rank.Tiebreak,
-	}, nil
+	resp := BestHandResponse{
+		BestHand:    best,
+		Category:    rank.Name(),
+		Tiebreak:    rank.Tiebreak,
+		Strength:    rank.Strength,
+		Description: rank.Description(),
+	}
+	for _, c := range rank.PlayedAs {
+		resp.PlayedAs = append(resp.PlayedAs, c.String())
+	}
+	return resp
 }
 
 func decodeJSON(r *http.Request, v any) error {
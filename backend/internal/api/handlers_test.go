@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// posts body to path on a fresh router and returns the response.
+func post(t *testing.T, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestOddsRejectsTooManyPlayers(t *testing.T) {
+	for _, players := range []string{"27", "1000000000000"} {
+		rec := post(t, "/api/v1/odds", `{"hole":["HA","SA"],"players":`+players+`,"simulations":100}`)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "not enough cards") {
+			t.Fatalf("players %s: got %d %s", players, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestBestHandDeadCards(t *testing.T) {
+	body := `{"hole":["C2","D3"],"community":["HA","SK","D9","C7","H5"],"relative":true,"dead":["C8","D8","H8","S8"]}`
+	if rec := post(t, "/api/v1/best-hand", body); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `8"`) {
+		t.Fatalf("dead eights: got %d %s", rec.Code, rec.Body.String())
+	}
+	body = `{"hole":["C2","D3"],"community":["HA","SK","D9"],"dead":["D3"]}`
+	if rec := post(t, "/api/v1/best-hand", body); rec.Code != http.StatusBadRequest {
+		t.Fatalf("dead hole card: got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPotsRequiresCompleteBoard(t *testing.T) {
+	players := `"players":[{"hole":["C2","D3"],"committed":100},{"hole":["S2","H3"],"committed":100}]`
+	if rec := post(t, "/api/v1/pots", `{`+players+`,"community":["HA","SK","D9"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("flop-only board: got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := post(t, "/api/v1/pots", `{`+players+`,"community":["HA","SK","D9","C7","H5"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("complete board: got %d %s", rec.Code, rec.Body.String())
+	}
+}
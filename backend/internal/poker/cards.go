@@ -3,13 +3,16 @@ package poker
 import (
 	"errors"
 	"fmt"
+	"math/bits"
 	"strings"
 )
 
-type Card struct {
-	Suit byte
-	Rank byte
-}
+// Card is a packed card index in 0..51: suit*13 + rank, where rank 0 is a deuce
+// and suits are ordered clubs, diamonds, hearts, spades. Joker follows them.
+type Card uint8
+
+// CardSet holds any subset of the 52 cards and the joker as a bitmask indexed by Card.
+type CardSet uint64
 
 const (
 	SuitClubs    = 'C'
@@ -18,69 +21,105 @@ const (
 	SuitSpades   = 'S'
 )
 
-var rankToValue = map[byte]int{
-	'2': 2,
-	'3': 3,
-	'4': 4,
-	'5': 5,
-	'6': 6,
-	'7': 7,
-	'8': 8,
-	'9': 9,
-	'T': 10,
-	'J': 11,
-	'Q': 12,
-	'K': 13,
-	'A': 14,
-}
-
-var valueToRank = map[int]byte{
-	2:  '2',
-	3:  '3',
-	4:  '4',
-	5:  '5',
-	6:  '6',
-	7:  '7',
-	8:  '8',
-	9:  '9',
-	10: 'T',
-	11: 'J',
-	12: 'Q',
-	13: 'K',
-	14: 'A',
-}
-
-// validates and parses a 2-character card code like "HA" or "S7".
+const (
+	numSuits = 4
+	numCards = numSuits * numRanks
+
+	// FullDeck is the set of all 52 cards.
+	FullDeck CardSet = 1<<numCards - 1
+
+	// Joker is the wild joker, written "XJ". It is never part of FullDeck.
+	Joker Card = numCards
+)
+
+const (
+	suitChars = "CDHS"
+	rankChars = "23456789TJQKA"
+	jokerName = "XJ"
+)
+
+// rankIndex and suitIndex map card characters to dense indexes (-1 if invalid)
+// so parsing and evaluation can avoid map lookups.
+var rankIndex, suitIndex [256]int8
+
+// cardNames holds the 2-character code of every card so String never allocates.
+var cardNames [numCards]string
+
+func init() {
+	for i := range rankIndex {
+		rankIndex[i] = -1
+		suitIndex[i] = -1
+	}
+	for i := 0; i < numRanks; i++ {
+		rankIndex[rankChars[i]] = int8(i)
+	}
+	for i := 0; i < numSuits; i++ {
+		suitIndex[suitChars[i]] = int8(i)
+	}
+	for c := Card(0); c < numCards; c++ {
+		cardNames[c] = string([]byte{c.Suit(), c.Rank()})
+	}
+}
+
+// builds a card from its suit and rank characters, e.g. ('H', 'A').
+func NewCard(suit, rank byte) (Card, error) {
+	s := suitIndex[suit]
+	if s < 0 {
+		return 0, fmt.Errorf("invalid suit '%c'", suit)
+	}
+	r := rankIndex[rank]
+	if r < 0 {
+		return 0, fmt.Errorf("invalid rank '%c'", rank)
+	}
+	return Card(int(s)*numRanks + int(r)), nil
+}
+
+// validates and parses a 2-character card code like "HA" or "S7". The joker
+// is only accepted by ParseWildCards.
 func ParseCard(s string) (Card, error) {
+	c, err := parseCard(s)
+	if err == nil && c == Joker {
+		return 0, fmt.Errorf("joker '%s' is only allowed with wild cards", jokerName)
+	}
+	return c, err
+}
+
+// parses a card code, accepting "XJ" for the joker.
+func parseCard(s string) (Card, error) {
 	s = strings.TrimSpace(strings.ToUpper(s))
 	if len(s) != 2 {
-		return Card{}, fmt.Errorf("invalid card '%s'", s)
+		return 0, fmt.Errorf("invalid card '%s'", s)
 	}
-	suit := s[0]
-	rank := s[1]
-	if suit != SuitClubs && suit != SuitDiamonds && suit != SuitHearts && suit != SuitSpades {
-		return Card{}, fmt.Errorf("invalid suit '%c'", suit)
+	if s == jokerName {
+		return Joker, nil
 	}
-	if _, ok := rankToValue[rank]; !ok {
-		return Card{}, fmt.Errorf("invalid rank '%c'", rank)
-	}
-	return Card{Suit: suit, Rank: rank}, nil
+	return NewCard(s[0], s[1])
 }
 
 // parses a slice of card codes and rejects duplicates.
 func ParseCards(list []string) ([]Card, error) {
+	return parseCards(list, ParseCard)
+}
+
+// parses a slice of card codes that may include the joker "XJ", for use with
+// EvaluateWild, and rejects duplicates.
+func ParseWildCards(list []string) ([]Card, error) {
+	return parseCards(list, parseCard)
+}
+
+// parses each card code with parse and rejects duplicates.
+func parseCards(list []string, parse func(string) (Card, error)) ([]Card, error) {
 	cards := make([]Card, 0, len(list))
-	seen := map[string]struct{}{}
+	var seen CardSet
 	for _, s := range list {
-		c, err := ParseCard(s)
+		c, err := parse(s)
 		if err != nil {
 			return nil, err
 		}
-		key := c.String()
-		if _, ok := seen[key]; ok {
-			return nil, fmt.Errorf("duplicate card '%s'", key)
+		if seen.Contains(c) {
+			return nil, fmt.Errorf("duplicate card '%s'", c)
 		}
-		seen[key] = struct{}{}
+		seen = seen.Add(c)
 		cards = append(cards, c)
 	}
 	return cards, nil
@@ -88,49 +127,132 @@ func ParseCards(list []string) ([]Card, error) {
 
 // returns the compact 2-character card code.
 func (c Card) String() string {
-	return fmt.Sprintf("%c%c", c.Suit, c.Rank)
+	if c == Joker {
+		return jokerName
+	}
+	if int(c) >= numCards {
+		return "??"
+	}
+	return cardNames[c]
+}
+
+// returns the suit character of the card.
+func (c Card) Suit() byte {
+	if c == Joker {
+		return jokerName[0]
+	}
+	return suitChars[c.suitIndex()]
+}
+
+// returns the rank character of the card.
+func (c Card) Rank() byte {
+	if c == Joker {
+		return jokerName[1]
+	}
+	return rankChars[c.rankIndex()]
 }
 
 // maps rank rune to numeric value (2..14).
 func (c Card) RankValue() int {
-	return rankToValue[c.Rank]
+	return c.rankIndex() + 2
+}
+
+func (c Card) suitIndex() int {
+	return int(c) / numRanks
+}
+
+func (c Card) rankIndex() int {
+	return int(c) % numRanks
 }
 
 // maps numeric value (2..14) to rank rune.
 func RankToChar(v int) (byte, error) {
-	r, ok := valueToRank[v]
-	if !ok {
+	if v < 2 || v > 14 {
 		return 0, errors.New("invalid rank value")
 	}
-	return r, nil
+	return rankChars[v-2], nil
+}
+
+// builds a set from the given cards.
+func NewCardSet(cs ...Card) CardSet {
+	var s CardSet
+	for _, c := range cs {
+		s = s.Add(c)
+	}
+	return s
+}
+
+// returns the set with c added.
+func (s CardSet) Add(c Card) CardSet {
+	return s | 1<<c
+}
+
+// returns the set with c removed.
+func (s CardSet) Remove(c Card) CardSet {
+	return s &^ (1 << c)
+}
+
+// reports whether c is in the set.
+func (s CardSet) Contains(c Card) bool {
+	return s&(1<<c) != 0
+}
+
+// returns the cards in either set.
+func (s CardSet) Union(o CardSet) CardSet {
+	return s | o
+}
+
+// returns the cards in both sets.
+func (s CardSet) Intersect(o CardSet) CardSet {
+	return s & o
+}
+
+// returns the cards in s that are not in o.
+func (s CardSet) Difference(o CardSet) CardSet {
+	return s &^ o
+}
+
+// returns the number of cards in the set.
+func (s CardSet) Count() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// calls fn for every card in the set in deck order.
+func (s CardSet) ForEach(fn func(Card)) {
+	for s != 0 {
+		c := Card(bits.TrailingZeros64(uint64(s)))
+		fn(c)
+		s &= s - 1
+	}
+}
+
+// returns the cards in the set in deck order.
+func (s CardSet) Cards() []Card {
+	cards := make([]Card, 0, s.Count())
+	s.ForEach(func(c Card) { cards = append(cards, c) })
+	return cards
+}
+
+// returns the 13-bit rank mask of the cards held in the given suit index.
+func (s CardSet) suitMask(suit int) uint16 {
+	return uint16(s>>(suit*numRanks)) & (1<<numRanks - 1)
 }
 
 // creates a standard 52-card deck in a deterministic order.
 func NewDeck() []Card {
-	suits := []byte{SuitClubs, SuitDiamonds, SuitHearts, SuitSpades}
-	ranks := []byte{'2', '3', '4', '5', '6', '7', '8', '9', 'T', 'J', 'Q', 'K', 'A'}
-	deck := make([]Card, 0, 52)
-	for _, s := range suits {
-		for _, r := range ranks {
-			deck = append(deck, Card{Suit: s, Rank: r})
-		}
-	}
-	return deck
+	return FullDeck.Cards()
 }
 
 // returns a deck with the specified cards removed.
 func RemoveCards(deck []Card, remove []Card) ([]Card, error) {
-	toRemove := map[string]struct{}{}
-	for _, c := range remove {
-		toRemove[c.String()] = struct{}{}
-	}
+	toRemove := NewCardSet(remove...)
 	filtered := make([]Card, 0, len(deck))
 	for _, c := range deck {
-		if _, ok := toRemove[c.String()]; !ok {
+		if !toRemove.Contains(c) {
 			filtered = append(filtered, c)
 		}
 	}
-	if len(deck)-len(filtered) != len(toRemove) {
+	if len(deck)-len(filtered) != toRemove.Count() {
 		return nil, errors.New("failed to remove cards from deck")
 	}
 	return filtered, nil
@@ -46,6 +46,18 @@ func TestComparison(t *testing.T) {
 			if got != want {
 				t.Fatalf("compare: got %d want %d", got, want)
 			}
+
+			s1, err := Score7(h1)
+			if err != nil {
+				t.Fatalf("score hand1: %v", err)
+			}
+			s2, err := Score7(h2)
+			if err != nil {
+				t.Fatalf("score hand2: %v", err)
+			}
+			if got := compareScores(s1, s2); got != want {
+				t.Fatalf("score compare: got %d want %d", got, want)
+			}
 		})
 	}
 }
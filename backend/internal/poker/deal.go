@@ -0,0 +1,274 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// deal is a validated equity problem: the known cards, the deck the rest
+// come from, and how many cards each part of the hand still needs.
+type deal struct {
+	r rules
+	// seats holds each player's known hole cards, empty if unknown; seat 0
+	// is the hero.
+	seats     [][]Card
+	community []Card
+	// deck is every card that can still be dealt.
+	deck []Card
+	// holeSize is how many cards each player holds at showdown.
+	holeSize        int
+	neededCommunity int
+	// ranges holds the combos a seat is drawn from, or nil if the seat is
+	// known or random; cumWeights holds their running weight totals.
+	ranges     []Range
+	cumWeights [][]float64
+}
+
+// validates an equity problem for a hero against random opponents, with the
+// dead cards out of the deck.
+func newDeal(v Variant, hole, community []Card, players int, dead []Card) (deal, error) {
+	r, err := v.rules()
+	if err != nil {
+		return deal{}, err
+	}
+	if err := r.checkHole(len(hole)); err != nil {
+		return deal{}, err
+	}
+	if players < 2 {
+		return deal{}, errors.New("players must be >= 2")
+	}
+	// check before allocating a seat per player, which may be any number
+	holeSize := r.dealtHole
+	if holeSize == 0 {
+		holeSize = len(hole)
+	}
+	if players > r.deck.Count()/holeSize {
+		return deal{}, errors.New("not enough cards in the deck for all players")
+	}
+	seats := make([][]Card, players)
+	seats[0] = hole
+	return newSeatDeal(v, seats, nil, community, dead)
+}
+
+// validates an equity problem where any seat's hole cards may be known or,
+// if ranges is not nil, drawn from ranges[seat]. Every known hand must be a
+// legal hand for the variant, and outside stud all known hands must be the
+// same size; ranges need a game with two hole cards. Dead cards are out of
+// the deck and must not be in any hand or on the board.
+func newSeatDeal(v Variant, seats [][]Card, ranges []Range, community []Card, dead []Card) (deal, error) {
+	r, err := v.rules()
+	if err != nil {
+		return deal{}, err
+	}
+	if len(seats) < 2 {
+		return deal{}, errors.New("players must be >= 2")
+	}
+	if r.boardCards == 0 && len(community) != 0 {
+		return deal{}, errors.New("community must be empty for stud games")
+	}
+	if !(len(community) == 0 || len(community) == 3 || len(community) == 4 || len(community) == 5) {
+		return deal{}, errors.New("community must have 0, 3, 4, or 5 cards")
+	}
+
+	d := deal{r: r, seats: seats, community: community, holeSize: r.dealtHole}
+	known := append([]Card{}, community...)
+	for _, hole := range seats {
+		if len(hole) == 0 {
+			continue
+		}
+		if err := r.checkHole(len(hole)); err != nil {
+			return deal{}, err
+		}
+		if d.holeSize == 0 {
+			d.holeSize = len(hole)
+		} else if r.dealtHole == 0 && len(hole) != d.holeSize {
+			return deal{}, fmt.Errorf("known hands must all have %d cards", d.holeSize)
+		}
+		known = append(known, hole...)
+	}
+	rangeSeats := 0
+	for i, rng := range ranges {
+		if rng == nil {
+			continue
+		}
+		if len(seats[i]) != 0 {
+			return deal{}, fmt.Errorf("player %d has both cards and a range", i+1)
+		}
+		if r.dealtHole != 0 || r.checkHole(2) != nil || (d.holeSize != 0 && d.holeSize != 2) {
+			return deal{}, fmt.Errorf("ranges are not supported for %s", v)
+		}
+		d.holeSize = 2
+		rangeSeats++
+	}
+	if d.holeSize == 0 {
+		return deal{}, errors.New("at least one hand must be known")
+	}
+	if err := r.checkCards(known, dead); err != nil {
+		return deal{}, err
+	}
+	if NewCardSet(known...).Count() != len(known) {
+		return deal{}, errors.New("cards must not repeat across hands and board")
+	}
+	deadSet := NewCardSet(dead...)
+	if deadSet.Count() != len(dead) {
+		return deal{}, errors.New("dead cards must not repeat")
+	}
+	for _, c := range known {
+		if deadSet.Contains(c) {
+			return deal{}, fmt.Errorf("dead card '%s' is also in a hand or on the board", c)
+		}
+	}
+	if rangeSeats > 0 {
+		// drop combos that hold a known or dead card or one the deck does not have
+		unavailable := NewCardSet(known...).Union(deadSet).Union(r.deck ^ FullDeck)
+		d.ranges = make([]Range, len(seats))
+		d.cumWeights = make([][]float64, len(seats))
+		for i, rng := range ranges {
+			if rng == nil {
+				continue
+			}
+			d.ranges[i] = rng.Without(unavailable)
+			if len(d.ranges[i]) == 0 {
+				return deal{}, fmt.Errorf("range for player %d has no combos left after card removal", i+1)
+			}
+			total := 0.0
+			for _, c := range d.ranges[i] {
+				total += c.Weight
+				d.cumWeights[i] = append(d.cumWeights[i], total)
+			}
+		}
+	}
+
+	// build a deck with all known and dead cards removed.
+	d.deck, err = RemoveCards(r.deck.Cards(), append(append([]Card{}, known...), dead...))
+	if err != nil {
+		return deal{}, err
+	}
+	d.neededCommunity = r.boardCards - len(community)
+	if d.neededCommunity+len(seats)*d.holeSize-(len(known)-len(community)) > len(d.deck) {
+		return deal{}, errors.New("not enough cards in the deck for all players")
+	}
+	return d, nil
+}
+
+// returns how many hole cards the seat still needs from the deck; range
+// seats draw theirs from the range.
+func (d deal) needed(seat int) int {
+	if d.isRange(seat) {
+		return 0
+	}
+	return d.holeSize - len(d.seats[seat])
+}
+
+// reports whether the seat's hand is drawn from a range.
+func (d deal) isRange(seat int) bool {
+	return d.ranges != nil && d.ranges[seat] != nil
+}
+
+// returns a combo from the seat's range, chosen in proportion to its weight.
+func (d deal) sampleRange(seat int, rng *rand.Rand) [2]Card {
+	cum := d.cumWeights[seat]
+	x := rng.Float64() * cum[len(cum)-1]
+	i := sort.Search(len(cum), func(i int) bool { return cum[i] > x })
+	return d.ranges[seat][min(i, len(cum)-1)].Cards
+}
+
+// returns the number of cards dealt to each part of the hand, in order: the
+// board, then every seat.
+func (d deal) groups() []int {
+	groups := []int{d.neededCommunity}
+	for i := range d.seats {
+		groups = append(groups, d.needed(i))
+	}
+	return groups
+}
+
+// returns a complete board and hands holding the known cards, ready for the
+// rest to be dealt into.
+func (d deal) buffers() (board []Card, holes [][]Card) {
+	board = make([]Card, d.r.boardCards)
+	copy(board, d.community)
+	holes = make([][]Card, len(d.seats))
+	for i, hole := range d.seats {
+		holes[i] = make([]Card, d.holeSize)
+		copy(holes[i], hole)
+	}
+	return board, holes
+}
+
+// fills in the cards still to come from cards, in the order of groups.
+func (d deal) fill(board []Card, holes [][]Card, cards []Card) {
+	idx := copy(board[len(d.community):], cards[:d.neededCommunity])
+	for i, hole := range holes {
+		idx += copy(hole[len(d.seats[i]):], cards[idx:idx+d.needed(i)])
+	}
+}
+
+// showdown holds the scratch space to settle one pot.
+type showdown struct {
+	high, low []Score
+	shares    []float64
+	// category is each player's high hand category, and oppCategory the
+	// category of the best high hand among everyone else.
+	category, oppCategory []Category
+}
+
+func newShowdown(players int) *showdown {
+	return &showdown{
+		high:        make([]Score, players),
+		low:         make([]Score, players),
+		shares:      make([]float64, players),
+		category:    make([]Category, players),
+		oppCategory: make([]Category, players),
+	}
+}
+
+// sets each player's fraction of the pot in s.shares, splitting ties evenly.
+func (d deal) settle(s *showdown, holes [][]Card, board []Card) error {
+	// track best hands and how many players share them (for ties)
+	var bestHigh, bestLow Score
+	highWinners, lowWinners := 0, 0
+	for i, hole := range holes {
+		high, low, err := d.r.showdown(hole, board)
+		if err != nil {
+			return err
+		}
+		s.high[i], s.low[i] = high, low
+		if i == 0 || high > bestHigh {
+			bestHigh = high
+			highWinners = 1
+		} else if high == bestHigh {
+			highWinners++
+		}
+		if low != 0 && low > bestLow {
+			bestLow = low
+			lowWinners = 1
+		} else if low != 0 && low == bestLow {
+			lowWinners++
+		}
+	}
+
+	// the best hand among everyone else is the best hand, unless the player
+	// holds it alone, in which case it is the runner-up
+	best, second := -1, -1
+	for i := range holes {
+		s.shares[i] = potShare(s.high[i], bestHigh, highWinners, s.low[i], bestLow, lowWinners)
+		s.category[i] = s.high[i].Category()
+		switch {
+		case best < 0 || s.high[i] > s.high[best]:
+			best, second = i, best
+		case second < 0 || s.high[i] > s.high[second]:
+			second = i
+		}
+	}
+	for i := range holes {
+		other := best
+		if i == best {
+			other = second
+		}
+		s.oppCategory[i] = s.high[other].Category()
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package poker
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// BoardTexture describes a Hold'em board.
+type BoardTexture struct {
+	// Monotone boards are all one suit, two-tone boards hold exactly two
+	// suits and rainbow boards never repeat a suit.
+	Monotone, TwoTone, Rainbow bool
+	// Paired boards repeat a rank; Trips boards hold three of one.
+	Paired, Trips bool
+	// Connected boards hold two adjacent ranks, the ace counting low too.
+	Connected bool
+	// StraightPossible and FlushPossible report whether two hole cards can
+	// make a straight or a flush with the board.
+	StraightPossible, FlushPossible bool
+	// Wet boards offer a straight or a flush, or draws to both at once.
+	Wet bool
+}
+
+// Draws lists the drawing hands the hero holds on a flop or turn. Straight
+// draws count only when a hole card plays and are not reported once the hero
+// has a straight, nor flush draws once the hero has a flush.
+type Draws struct {
+	// FlushDraw is four cards to a flush including a hole card.
+	FlushDraw bool
+	// OpenEnded is two or more ranks completing a straight (an open-ended
+	// or double gutshot draw), Gutshot exactly one.
+	OpenEnded, Gutshot bool
+	// StraightRanks is the number of ranks that complete a straight.
+	StraightRanks int
+	// BackdoorFlush and BackdoorStraight need both the turn and the river
+	// and are only reported on the flop, when no regular draw of the same
+	// kind is held.
+	BackdoorFlush, BackdoorStraight bool
+	// Overcards is how many unpaired hole cards outrank every board card.
+	Overcards int
+}
+
+// AnalyzeBoard classifies a Hold'em board of 3 to 5 cards.
+func AnalyzeBoard(board []Card) (BoardTexture, error) {
+	if len(board) < 3 || len(board) > 5 {
+		return BoardTexture{}, errors.New("community must have 3, 4, or 5 cards")
+	}
+	if err := checkDistinct(board); err != nil {
+		return BoardTexture{}, err
+	}
+
+	var t BoardTexture
+	var suits [numSuits]int
+	var ranks [numRanks]int
+	for _, c := range board {
+		suits[c.suitIndex()]++
+		ranks[c.rankIndex()]++
+	}
+	present, top := 0, 0
+	for _, n := range suits {
+		if n > 0 {
+			present++
+		}
+		top = max(top, n)
+	}
+	t.Monotone = present == 1
+	t.TwoTone = present == 2
+	t.Rainbow = top == 1
+	t.FlushPossible = top >= 3
+	for _, n := range ranks {
+		t.Paired = t.Paired || n >= 2
+		t.Trips = t.Trips || n >= 3
+	}
+
+	mask := rankMask(board)
+	// the ace also sits below the deuce
+	t.Connected = mask&(mask<<1|mask>>(numRanks-1)) != 0
+	for r1 := 0; r1 < numRanks && !t.StraightPossible; r1++ {
+		for r2 := r1; r2 < numRanks; r2++ {
+			if ok, _ := straightHigh(mask|1<<r1|1<<r2, standardTables.wheelHigh); ok {
+				t.StraightPossible = true
+				break
+			}
+		}
+	}
+	t.Wet = t.StraightPossible || t.FlushPossible || (top == 2 && t.Connected)
+	return t, nil
+}
+
+// AnalyzeDraws reports the Hold'em draws two hole cards hold on a 3 or 4
+// card board.
+func AnalyzeDraws(hole, board []Card) (Draws, error) {
+	if len(hole) != 2 {
+		return Draws{}, errors.New("hole must have 2 cards")
+	}
+	if len(board) != 3 && len(board) != 4 {
+		return Draws{}, errors.New("community must have 3 or 4 cards")
+	}
+	all := append(append([]Card{}, hole...), board...)
+	if err := checkDistinct(all); err != nil {
+		return Draws{}, err
+	}
+
+	var d Draws
+	set, holeSet := NewCardSet(all...), NewCardSet(hole...)
+	flush := false
+	for s := 0; s < numSuits; s++ {
+		n := bits.OnesCount16(set.suitMask(s))
+		mine := holeSet.suitMask(s) != 0
+		flush = flush || n >= 5
+		d.FlushDraw = d.FlushDraw || (n == 4 && mine)
+		d.BackdoorFlush = d.BackdoorFlush || (n == 3 && mine && len(board) == 3)
+	}
+	if flush {
+		d.FlushDraw, d.BackdoorFlush = false, false
+	}
+	d.BackdoorFlush = d.BackdoorFlush && !d.FlushDraw
+
+	mine, shared := rankMask(all), rankMask(board)
+	if ok, _ := straightHigh(mine, standardTables.wheelHigh); !ok {
+		for r := 0; r < numRanks; r++ {
+			if mine&(1<<r) == 0 && improvesStraight(mine|1<<r, shared|1<<r) {
+				d.StraightRanks++
+			}
+		}
+		d.OpenEnded = d.StraightRanks >= 2
+		d.Gutshot = d.StraightRanks == 1
+		if d.StraightRanks == 0 && len(board) == 3 {
+			for r1 := 0; r1 < numRanks && !d.BackdoorStraight; r1++ {
+				for r2 := r1 + 1; r2 < numRanks; r2++ {
+					add := uint16(1<<r1 | 1<<r2)
+					if mine&add == 0 && improvesStraight(mine|add, shared|add) {
+						d.BackdoorStraight = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	// a pocket pair above the board is an overpair, not two overcards
+	high := bits.Len16(shared) - 1
+	for _, c := range hole {
+		if c.rankIndex() > high && hole[0].rankIndex() != hole[1].rankIndex() {
+			d.Overcards++
+		}
+	}
+	return d, nil
+}
+
+// reports whether the hero's ranks make a straight higher than any the
+// board's ranks make alone.
+func improvesStraight(mine, shared uint16) bool {
+	ok, high := straightHigh(mine, standardTables.wheelHigh)
+	if !ok {
+		return false
+	}
+	_, boardHigh := straightHigh(shared, standardTables.wheelHigh)
+	return high > boardHigh
+}
+
+// returns the 13-bit mask of the ranks among the cards.
+func rankMask(cs []Card) uint16 {
+	var mask uint16
+	for _, c := range cs {
+		mask |= 1 << c.rankIndex()
+	}
+	return mask
+}
+
+// checks that every card is a distinct card of the standard deck.
+func checkDistinct(cs []Card) error {
+	if err := checkDeck(cs, FullDeck); err != nil {
+		return err
+	}
+	if NewCardSet(cs...).Count() != len(cs) {
+		return errors.New("cards must not repeat")
+	}
+	return nil
+}
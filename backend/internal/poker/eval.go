@@ -2,7 +2,8 @@ package poker
 
 import (
 	"errors"
-	"sort"
+	"fmt"
+	"math/bits"
 )
 
 type Category int
@@ -17,6 +18,8 @@ const (
 	FullHouse
 	FourOfAKind
 	StraightFlush
+	// FiveOfAKind is only possible with wild cards.
+	FiveOfAKind
 )
 
 var categoryName = map[Category]string{
@@ -29,131 +32,134 @@ var categoryName = map[Category]string{
 	FullHouse:     "full house",
 	FourOfAKind:   "four of a kind",
 	StraightFlush: "straight flush",
+	FiveOfAKind:   "five of a kind",
 }
 
+// cards contributed by each tiebreak rank, in tiebreak order.
+var categoryShape = map[Category][]int{
+	HighCard:     {1, 1, 1, 1, 1},
+	OnePair:      {2, 1, 1, 1},
+	TwoPair:      {2, 2, 1},
+	ThreeOfAKind: {3, 1, 1},
+	Flush:        {1, 1, 1, 1, 1},
+	FullHouse:    {3, 2},
+	FourOfAKind:  {4, 1},
+}
+
+var (
+	errEvaluate7   = errors.New("Evaluate7 expects 7 cards")
+	errHandSize    = fmt.Errorf("hand must have %d to %d cards", minEvalCards, maxEvalCards)
+	errInvalidCard = errors.New("invalid card in hand")
+)
+
 type HandRank struct {
 	Category Category
 	Tiebreak []int
 	Best5    []Card
+	// Strength is the hand's equivalence class in 1..NumStrengthClasses;
+	// higher is stronger and equal strengths tie.
+	Strength int
+	// Score is the packed hand value; Compare prefers it when both hands
+	// carry one so that variant-specific category orders are respected.
+	Score Score
+	// PlayedAs is set when wild cards are involved: PlayedAs[i] is the
+	// natural card that Best5[i] plays as.
+	PlayedAs []Card
 }
 
-func (h HandRank) Name() string {
-	return categoryName[h.Category]
+func newHandRank(score Score, best5 []Card) HandRank {
+	return HandRank{
+		Category: score.Category(),
+		Tiebreak: score.Tiebreak(),
+		Best5:    best5,
+		Strength: score.Strength(),
+		Score:    score,
+	}
 }
 
-// computes the best 5-card hand from exactly 7 cards.
-func Evaluate7(cs []Card) (HandRank, error) {
-	if len(cs) != 7 {
-		return HandRank{}, errors.New("Evaluate7 expects 7 cards")
-	}
-	best := HandRank{Category: -1}
-	combos := combinations7to5(cs)
-	for _, combo := range combos {
-		r := Evaluate5(combo)
-		if best.Category == -1 || Compare(r, best) > 0 {
-			best = r
-		}
-	}
-	return best, nil
+func (c Category) String() string {
+	return categoryName[c]
 }
 
-// ranks a 5-card hand and returns its category and tiebreakers.
-func Evaluate5(cs []Card) HandRank {
-	ranks := make([]int, 0, 5)
-	suits := make(map[byte]int)
-	counts := make(map[int]int)
-	for _, c := range cs {
-		v := c.RankValue()
-		ranks = append(ranks, v)
-		counts[v]++
-		suits[c.Suit]++
-	}
-	sort.Ints(ranks)
-	sort.Slice(ranks, func(i, j int) bool { return ranks[i] > ranks[j] })
+func (h HandRank) Name() string {
+	return h.Category.String()
+}
 
-	isFlush := false
-	for _, n := range suits {
-		if n == 5 {
-			isFlush = true
-			break
-		}
+// returns a description of the hand like "Pair of Aces, K-9-7 kicker".
+func (h HandRank) Description() string {
+	if h.Score == 0 {
+		return h.Name()
 	}
+	return h.Score.Describe()
+}
 
-	isStraight, straightHigh := straightHigh(ranks)
-
-	if isStraight && isFlush {
-		return HandRank{
-			Category: StraightFlush,
-			Tiebreak: []int{straightHigh},
-			Best5:    append([]Card{}, cs...),
-		}
+// computes the best 5-card hand from exactly 7 cards.
+func Evaluate7(cs []Card) (HandRank, error) {
+	if len(cs) != 7 {
+		return HandRank{}, errEvaluate7
 	}
+	return EvaluateN(cs)
+}
 
-	if rank, kicker, ok := fourOfAKind(counts); ok {
-		return HandRank{
-			Category: FourOfAKind,
-			Tiebreak: []int{rank, kicker},
-			Best5:    append([]Card{}, cs...),
-		}
+// computes the best 5-card hand from any 5 to 9 cards.
+func EvaluateN(cs []Card) (HandRank, error) {
+	score, err := ScoreN(cs)
+	if err != nil {
+		return HandRank{}, err
 	}
+	return newHandRank(score, bestFive(cs, score)), nil
+}
 
-	if trip, pair, ok := fullHouse(counts); ok {
-		return HandRank{
-			Category: FullHouse,
-			Tiebreak: []int{trip, pair},
-			Best5:    append([]Card{}, cs...),
-		}
-	}
+// ScoreN scores the best 5-card hand from 5 to 9 cards without allocating.
+func ScoreN(cs []Card) (Score, error) {
+	return scoreCards(cs)
+}
 
-	if isFlush {
-		return HandRank{
-			Category: Flush,
-			Tiebreak: append([]int{}, ranks...),
-			Best5:    append([]Card{}, cs...),
-		}
+// Score7 scores exactly 7 cards without allocating, for use in hot loops.
+func Score7(cs []Card) (Score, error) {
+	if len(cs) != 7 {
+		return 0, errEvaluate7
 	}
+	return scoreCards(cs)
+}
 
-	if isStraight {
-		return HandRank{
-			Category: Straight,
-			Tiebreak: []int{straightHigh},
-			Best5:    append([]Card{}, cs...),
-		}
-	}
+// ranks a 5-card hand and returns its category and tiebreakers, under the
+// standard rules or those of the variant given; short deck ranks a flush above
+// a full house. Invalid cards or variants give the zero HandRank.
+func Evaluate5(cs []Card, v ...Variant) HandRank {
+	score, err := scoreCards(cs)
+	if len(v) > 0 {
+		score, err = scoreVariant5(v[0], cs)
+	}
+	if err != nil {
+		return HandRank{}
+	}
+	return newHandRank(score, append([]Card{}, cs...))
+}
 
-	if trip, kickers, ok := threeOfAKind(counts); ok {
-		return HandRank{
-			Category: ThreeOfAKind,
-			Tiebreak: append([]int{trip}, kickers...),
-			Best5:    append([]Card{}, cs...),
-		}
+// scores five cards under the variant's ranking and deck.
+func scoreVariant5(v Variant, cs []Card) (Score, error) {
+	r, err := v.rules()
+	if err != nil {
+		return 0, err
 	}
-
-	if highPair, lowPair, kicker, ok := twoPair(counts); ok {
-		return HandRank{
-			Category: TwoPair,
-			Tiebreak: []int{highPair, lowPair, kicker},
-			Best5:    append([]Card{}, cs...),
-		}
+	if len(cs) != 5 {
+		return 0, errors.New("hand must have 5 cards")
 	}
-
-	if pair, kickers, ok := onePair(counts); ok {
-		return HandRank{
-			Category: OnePair,
-			Tiebreak: append([]int{pair}, kickers...),
-			Best5:    append([]Card{}, cs...),
-		}
+	if err := r.checkCards(cs); err != nil {
+		return 0, err
 	}
-
-	return HandRank{
-		Category: HighCard,
-		Tiebreak: append([]int{}, ranks...),
-		Best5:    append([]Card{}, cs...),
+	if v == ShortDeckHoldem {
+		return ScoreShortDeck(cs)
 	}
+	return scoreCards(cs)
 }
 
 // compares two ranked hands: 1 if a > b, -1 if a < b, 0 if equal.
 func Compare(a, b HandRank) int {
+	if a.Score != 0 && b.Score != 0 {
+		return compareScores(a.Score, b.Score)
+	}
 	if a.Category != b.Category {
 		if a.Category > b.Category {
 			return 1
@@ -175,145 +181,118 @@ func Compare(a, b HandRank) int {
 	return 0
 }
 
-// determines if the ranks form a straight and returns its high card.
-func straightHigh(ranks []int) (bool, int) {
-	unique := make([]int, 0, 5)
-	seen := map[int]struct{}{}
-	for _, r := range ranks {
-		if _, ok := seen[r]; !ok {
-			unique = append(unique, r)
-			seen[r] = struct{}{}
-		}
-	}
-	sort.Slice(unique, func(i, j int) bool { return unique[i] > unique[j] })
-	if len(unique) != 5 {
-		return false, 0
-	}
-	if unique[0]-unique[4] == 4 {
-		return true, unique[0]
-	}
-	if unique[0] == 14 && unique[1] == 5 && unique[2] == 4 && unique[3] == 3 && unique[4] == 2 {
-		return true, 5
+// compares two scores from the same rules: 1 if a > b, -1 if a < b, 0 if equal.
+func compareScores(a, b Score) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	default:
+		return 0
 	}
-	return false, 0
 }
 
-// returns the quad rank and kicker rank if present.
-func fourOfAKind(counts map[int]int) (int, int, bool) {
-	quad := 0
-	kicker := 0
-	for r, c := range counts {
-		if c == 4 {
-			quad = r
-		} else if c == 1 {
-			kicker = r
-		}
-	}
-	if quad == 0 {
-		return 0, 0, false
-	}
-	return quad, kicker, true
+// scores cards under the standard 52-card rules.
+func scoreCards(cs []Card) (Score, error) {
+	return standardTables.score(cs)
 }
 
-// returns trip and pair ranks if present.
-func fullHouse(counts map[int]int) (int, int, bool) {
-	trip := 0
-	pair := 0
-	for r, c := range counts {
-		if c == 3 && r > trip {
-			trip = r
-		}
+// looks up the best 5-card score among the given cards using the rank and flush tables.
+func (t *rankTables) score(cs []Card) (Score, error) {
+	if len(cs) < minEvalCards || len(cs) > t.maxCards {
+		return 0, errHandSize
 	}
-	if trip == 0 {
-		return 0, 0, false
+	var counts [numRanks]uint8
+	var set CardSet
+	for _, c := range cs {
+		if int(c) >= numCards {
+			return 0, errInvalidCard
+		}
+		set = set.Add(c)
+		counts[c.rankIndex()]++
+		if counts[c.rankIndex()] > maxRankCount {
+			return 0, errInvalidCard
+		}
 	}
-	for r, c := range counts {
-		if c >= 2 && r != trip {
-			if r > pair {
-				pair = r
+	score := t.rankTable[hashRankCounts(&counts, len(cs))]
+	for s := 0; s < numSuits; s++ {
+		if m := set.suitMask(s); bits.OnesCount16(m) >= 5 {
+			// a five-card low is scored on its flush alone, since the flush is
+			// what the hand is as a high hand.
+			if f := t.flushTable[m]; f != 0 && (t.low || f > score) {
+				score = f
 			}
 		}
 	}
-	if pair == 0 {
-		return 0, 0, false
-	}
-	return trip, pair, true
+	return score, nil
 }
 
-// returns trip rank and kickers if present.
-func threeOfAKind(counts map[int]int) (int, []int, bool) {
-	trip := 0
-	for r, c := range counts {
-		if c == 3 && r > trip {
-			trip = r
+// picks the five cards that make up the given score, keeping input order.
+func bestFive(cs []Card, score Score) []Card {
+	var want [15]int
+	cat := score.Category()
+	tb := score.Tiebreak()
+	switch cat {
+	case Straight, StraightFlush:
+		for v := tb[0]; v > tb[0]-4; v-- {
+			want[v] = 1
+		}
+		if tb[0] == score.tables().wheelHigh {
+			want[14] = 1
+		} else {
+			want[tb[0]-4] = 1
+		}
+	default:
+		for i, v := range tb {
+			want[v] += categoryShape[cat][i]
 		}
 	}
-	if trip == 0 {
-		return 0, nil, false
+	var suit byte
+	if cat == Flush || cat == StraightFlush {
+		suit = flushSuit(cs)
 	}
-	kickers := make([]int, 0, 2)
-	for r, c := range counts {
-		if c == 1 {
-			kickers = append(kickers, r)
+	best := make([]Card, 0, 5)
+	for _, c := range cs {
+		if suit != 0 && c.Suit() != suit {
+			continue
+		}
+		if v := c.RankValue(); want[v] > 0 {
+			want[v]--
+			best = append(best, c)
 		}
 	}
-	sort.Slice(kickers, func(i, j int) bool { return kickers[i] > kickers[j] })
-	return trip, kickers, true
+	return best
 }
 
-// returns high pair, low pair, and kicker if present.
-func twoPair(counts map[int]int) (int, int, int, bool) {
-	pairs := make([]int, 0, 2)
-	kicker := 0
-	for r, c := range counts {
-		if c == 2 {
-			pairs = append(pairs, r)
-		} else if c == 1 {
-			kicker = r
+// returns the suit held at least five times, or 0 if there is none.
+func flushSuit(cs []Card) byte {
+	var counts [numSuits]int
+	for _, c := range cs {
+		counts[c.suitIndex()]++
+		if counts[c.suitIndex()] >= 5 {
+			return c.Suit()
 		}
 	}
-	if len(pairs) != 2 {
-		return 0, 0, 0, false
-	}
-	sort.Slice(pairs, func(i, j int) bool { return pairs[i] > pairs[j] })
-	return pairs[0], pairs[1], kicker, true
+	return 0
 }
 
-// returns pair rank and kickers if present.
-func onePair(counts map[int]int) (int, []int, bool) {
-	pair := 0
-	for r, c := range counts {
-		if c == 2 && r > pair {
-			pair = r
+// determines if the rank mask contains a straight and returns its high card.
+// wheelHigh is the top card of the lowest straight, where the ace plays low:
+// 5 for A-2-3-4-5, 9 for the short-deck A-6-7-8-9, or 0 for none.
+func straightHigh(mask uint16, wheelHigh int) (bool, int) {
+	const five = 0x1f
+	for i := numRanks - 5; i >= 0; i-- {
+		if mask>>i&five == five {
+			return true, i + 6
 		}
 	}
-	if pair == 0 {
-		return 0, nil, false
+	if wheelHigh == 0 {
+		return false, 0
 	}
-	kickers := make([]int, 0, 3)
-	for r, c := range counts {
-		if c == 1 {
-			kickers = append(kickers, r)
-		}
+	wheel := uint16(1<<12 | 0xf<<(wheelHigh-5))
+	if mask&wheel == wheel {
+		return true, wheelHigh
 	}
-	sort.Slice(kickers, func(i, j int) bool { return kickers[i] > kickers[j] })
-	return pair, kickers, true
-}
-
-// enumerates all 21 combinations of 5 cards from 7.
-func combinations7to5(cs []Card) [][]Card {
-	combos := make([][]Card, 0, 21)
-	for i := 0; i < 7; i++ {
-		for j := i + 1; j < 7; j++ {
-			combo := make([]Card, 0, 5)
-			for k := 0; k < 7; k++ {
-				if k == i || k == j {
-					continue
-				}
-				combo = append(combo, cs[k])
-			}
-			combos = append(combos, combo)
-		}
-	}
-	return combos
+	return false, 0
 }
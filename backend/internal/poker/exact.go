@@ -0,0 +1,277 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Method selects how equity is computed.
+type Method int
+
+const (
+	// MethodAuto enumerates exactly when the deal is small enough and
+	// samples otherwise.
+	MethodAuto Method = iota
+	MethodExact
+	MethodMonteCarlo
+)
+
+var methodName = map[Method]string{
+	MethodAuto:       "auto",
+	MethodExact:      "exact",
+	MethodMonteCarlo: "montecarlo",
+}
+
+// ExactLimit is the most deals Exact will enumerate for heads-up Hold'em. One
+// opponent from the flop onwards is about a million deals. Other games get
+// the same amount of work: the limit shrinks with the hand evaluations each
+// deal costs, so Omaha hi-lo allows far fewer deals.
+const ExactLimit = 2_000_000
+
+// ctxCheckDeals is how often, in deals, enumeration checks for cancellation.
+const ctxCheckDeals = 4096
+
+var errExactLimit = fmt.Errorf("too many deals to enumerate exactly (limit %d heads-up Hold'em deals or the same work)", ExactLimit)
+
+func (m Method) String() string {
+	if name, ok := methodName[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("method(%d)", int(m))
+}
+
+// ParseMethod maps a method name to a Method; an empty name means auto.
+func ParseMethod(s string) (Method, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return MethodAuto, nil
+	}
+	for m, name := range methodName {
+		if name == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid method '%s'", s)
+}
+
+// Equity computes each player's share of the pot with the given method, the
+// hero first and then each opponent dealt at random, stopping with ctx's error
+// when ctx is cancelled. The results report the method actually used: auto
+// resolves to exact enumeration when the deals fit within ExactLimit, and to a
+// Monte Carlo simulation run with opts otherwise. With a time budget, auto
+// falls back to a simulation for the rest of the budget when enumeration takes
+// more than half of it; an explicit exact method ignores the budget.
+func Equity(ctx context.Context, v Variant, m Method, hole, community []Card, players int, opts MonteCarloOptions) ([]EquityResult, error) {
+	d, err := newDeal(v, hole, community, players, opts.Dead)
+	if err != nil {
+		return nil, err
+	}
+	return d.equity(ctx, m, opts)
+}
+
+// EquityHands is Equity for several players at once, returning one result
+// per hand. Any hand may be empty to deal that player random cards.
+func EquityHands(ctx context.Context, v Variant, m Method, hands [][]Card, community []Card, opts MonteCarloOptions) ([]EquityResult, error) {
+	d, err := newSeatDeal(v, hands, nil, community, opts.Dead)
+	if err != nil {
+		return nil, err
+	}
+	return d.equity(ctx, m, opts)
+}
+
+// EquityRanges is EquityHands for players holding ranges, returning one
+// result per range. Combos that share a card with the board, or with each
+// other in the same trial, are never dealt together. A range of a single
+// combo is a known hand; any other range is sampled, so auto always resolves
+// to a Monte Carlo simulation and exact is only possible without them.
+func EquityRanges(ctx context.Context, v Variant, m Method, ranges []Range, community []Card, opts MonteCarloOptions) ([]EquityResult, error) {
+	seats := make([][]Card, len(ranges))
+	sampled := make([]Range, len(ranges))
+	for i, r := range ranges {
+		switch len(r) {
+		case 0:
+			return nil, fmt.Errorf("range for player %d is empty", i+1)
+		case 1:
+			seats[i] = r[0].Cards[:]
+		default:
+			sampled[i] = r
+		}
+	}
+	d, err := newSeatDeal(v, seats, sampled, community, opts.Dead)
+	if err != nil {
+		return nil, err
+	}
+	return d.equity(ctx, m, opts)
+}
+
+// computes every seat's equity with the given method.
+func (d deal) equity(ctx context.Context, m Method, opts MonteCarloOptions) ([]EquityResult, error) {
+	if d.ranges != nil {
+		if m == MethodExact {
+			return nil, errors.New("exact enumeration does not support ranges")
+		}
+		return d.simulate(ctx, opts)
+	}
+	switch m {
+	case MethodAuto:
+		if !d.enumerable() {
+			return d.simulate(ctx, opts)
+		}
+		if opts.TimeBudget <= 0 {
+			return d.exact(ctx, opts.Pool)
+		}
+		// enumerate within half the budget, then sample for what is left
+		start := time.Now()
+		exactCtx, cancel := context.WithTimeout(ctx, opts.TimeBudget/2)
+		res, err := d.exact(exactCtx, opts.Pool)
+		cancel()
+		if err == nil || ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+			return res, err
+		}
+		opts.TimeBudget = max(opts.TimeBudget-time.Since(start), time.Nanosecond)
+		return d.simulate(ctx, opts)
+	case MethodExact:
+		if !d.enumerable() {
+			return nil, errExactLimit
+		}
+		return d.exact(ctx, opts.Pool)
+	case MethodMonteCarlo:
+		return d.simulate(ctx, opts)
+	}
+	return nil, fmt.Errorf("unsupported method %s", m)
+}
+
+// Exact computes the hero's share of the pot by walking every way the rest
+// of the board and the opponents' hole cards can be dealt. It fails when
+// there are more deals than ExactLimit allows.
+func Exact(v Variant, hole, community []Card, players int) (EquityResult, error) {
+	return ExactContext(context.Background(), v, hole, community, players)
+}
+
+// ExactContext is Exact that stops with ctx's error when ctx is cancelled.
+func ExactContext(ctx context.Context, v Variant, hole, community []Card, players int) (EquityResult, error) {
+	res, err := Equity(ctx, v, MethodExact, hole, community, players, MonteCarloOptions{})
+	if err != nil {
+		return EquityResult{}, err
+	}
+	return res[0], nil
+}
+
+// returns the number of distinct deals: board cards, then each seat's
+// missing cards in seat order.
+func (d deal) combinations() float64 {
+	n := len(d.deck)
+	total := 1.0
+	for _, k := range d.groups() {
+		total *= binomial(n, k)
+		n -= k
+	}
+	return total
+}
+
+// reports whether enumerating every deal is within ExactLimit, counting the
+// work as heads-up Hold'em deals of one lookup per seat.
+func (d deal) enumerable() bool {
+	return d.combinations()*d.evalsPerDeal() <= ExactLimit*2
+}
+
+// returns how many five-card lookups scoring one deal takes: each seat tries
+// every way of playing the required hole cards with the board, and hi-lo
+// games score the low as well.
+func (d deal) evalsPerDeal() float64 {
+	per := 1.0
+	if d.r.mustPlay > 0 {
+		per = binomial(d.holeSize, d.r.mustPlay) * binomial(d.r.boardCards, minEvalCards-d.r.mustPlay)
+	}
+	if d.r.low != nil {
+		per *= 2
+	}
+	return per * float64(len(d.seats))
+}
+
+// averages every seat's pot share over every deal, holding one of the
+// pool's slots for the whole enumeration.
+func (d deal) exact(ctx context.Context, pool *WorkerPool) ([]EquityResult, error) {
+	if err := pool.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer pool.release()
+
+	board, holes := d.buffers()
+	s := newShowdown(len(d.seats))
+	tallies := make([]tally, len(d.seats))
+	groups := d.groups()
+	total := 0
+	for _, k := range groups {
+		total += k
+	}
+	out := make([]Card, total)
+	used := make([]bool, len(d.deck))
+	deadline, ok := ctx.Deadline()
+
+	// fills out[pos:] group by group, each group in increasing deck order
+	// so that no hand is counted twice
+	var walk func(g, pos, end, start int) error
+	walk = func(g, pos, end, start int) error {
+		for pos == end {
+			if g == len(groups) {
+				if tallies[0].deals%ctxCheckDeals == 0 {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					// the deadline timer may not fire while this goroutine
+					// keeps the only CPU busy
+					if ok && time.Now().After(deadline) {
+						return context.DeadlineExceeded
+					}
+				}
+				d.fill(board, holes, out)
+				if err := d.settle(s, holes, board); err != nil {
+					return err
+				}
+				for i := range tallies {
+					tallies[i].add(s.shares[i], s.category[i], s.oppCategory[i])
+				}
+				return nil
+			}
+			end += groups[g]
+			g++
+			start = 0
+		}
+		for i := start; i < len(d.deck); i++ {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			out[pos] = d.deck[i]
+			if err := walk(g, pos+1, end, i+1); err != nil {
+				return err
+			}
+			used[i] = false
+		}
+		return nil
+	}
+	if err := walk(0, 0, 0, 0); err != nil {
+		return nil, err
+	}
+	res := make([]EquityResult, len(tallies))
+	for i, t := range tallies {
+		res[i] = t.result(MethodExact, 0)
+	}
+	return res, nil
+}
+
+// returns n choose k.
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	r := 1.0
+	for i := 0; i < k; i++ {
+		r = r * float64(n-i) / float64(i+1)
+	}
+	return r
+}
@@ -0,0 +1,79 @@
+package poker
+
+// eightOrBetter is the worst ace-to-five low that qualifies for the low half
+// of a hi-lo pot: 8-7-6-5-4. Every better low scores higher. It is set once
+// the tables are built.
+var eightOrBetter Score
+
+// EvaluateOmahaLow computes the best eight-or-better Omaha low, using exactly
+// two hole cards and three board cards. ok is false when no low qualifies.
+func EvaluateOmahaLow(hole, board []Card) (rank HandRank, ok bool, err error) {
+	score, best, err := scoreOmaha(aceToFiveTables, hole, board)
+	if err != nil || score < eightOrBetter {
+		return HandRank{}, false, err
+	}
+	return newHandRank(score, append([]Card{}, best[:]...)), true, nil
+}
+
+// EvaluateStudLow computes the best eight-or-better low from 5 to 7 stud
+// cards. ok is false when no low qualifies.
+func EvaluateStudLow(cs []Card) (rank HandRank, ok bool, err error) {
+	score, best, err := scoreLow(aceToFiveTables, cs)
+	if err != nil || score < eightOrBetter {
+		return HandRank{}, false, err
+	}
+	return newHandRank(score, append([]Card{}, best[:]...)), true, nil
+}
+
+// scores a qualifying Omaha low, or returns 0 when there is none.
+func scoreOmahaLow(hole, board []Card) (Score, error) {
+	score, _, err := scoreOmaha(aceToFiveTables, hole, board)
+	if err != nil || score < eightOrBetter {
+		return 0, err
+	}
+	return score, nil
+}
+
+// scores a qualifying stud low, or returns 0 when there is none.
+func scoreStudLow(hole, _ []Card) (Score, error) {
+	score, _, err := scoreLow(aceToFiveTables, hole)
+	if err != nil || score < eightOrBetter {
+		return 0, err
+	}
+	return score, nil
+}
+
+func scoreStud(hole, _ []Card) (Score, error) {
+	return scoreCards(hole)
+}
+
+func evaluateStud(hole, _ []Card) (HandRank, error) {
+	return EvaluateN(hole)
+}
+
+func evaluateOmahaLow(hole, board []Card) (HandRank, bool, error) {
+	return EvaluateOmahaLow(hole, board)
+}
+
+func evaluateStudLow(hole, _ []Card) (HandRank, bool, error) {
+	return EvaluateStudLow(hole)
+}
+
+// splits one pot between the best high hands and the best qualifying lows and
+// returns the hero's fraction. A pot with no qualifying low goes to the high
+// hands alone; ties split their half evenly, which quarters a pot when two
+// players share the low.
+func potShare(heroHigh, bestHigh Score, highWinners int, heroLow, bestLow Score, lowWinners int) float64 {
+	highPot := 1.0
+	share := 0.0
+	if lowWinners > 0 {
+		highPot = 0.5
+		if heroLow != 0 && heroLow == bestLow {
+			share += 0.5 / float64(lowWinners)
+		}
+	}
+	if heroHigh == bestHigh {
+		share += highPot / float64(highWinners)
+	}
+	return share
+}
@@ -1,6 +1,16 @@
 package poker
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestParseCardValidation(t *testing.T) {
 	if _, err := ParseCard("HA"); err != nil {
@@ -21,6 +31,45 @@ func TestParseCardValidation(t *testing.T) {
 	}
 }
 
+func TestCardSet(t *testing.T) {
+	deck := NewDeck()
+	if len(deck) != 52 {
+		t.Fatalf("deck size: got %d want 52", len(deck))
+	}
+	for i, c := range deck {
+		if int(c) != i {
+			t.Fatalf("deck order: card %d is %v", i, c)
+		}
+		parsed, err := ParseCard(c.String())
+		if err != nil || parsed != c {
+			t.Fatalf("round trip %v: got %v, %v", c, parsed, err)
+		}
+	}
+
+	a := NewCardSet(mustParseCards(t, "HA SK C2")...)
+	b := NewCardSet(mustParseCards(t, "SK D9")...)
+	if got := a.Union(b).Count(); got != 4 {
+		t.Fatalf("union count: got %d want 4", got)
+	}
+	if got := a.Intersect(b).Cards(); len(got) != 1 || got[0].String() != "SK" {
+		t.Fatalf("intersect: got %v", got)
+	}
+	if a.Difference(b).Contains(mustParseCards(t, "SK")[0]) {
+		t.Fatalf("difference still contains SK")
+	}
+	if got := FullDeck.Difference(a).Count(); got != 49 {
+		t.Fatalf("remaining deck: got %d want 49", got)
+	}
+
+	rest, err := RemoveCards(deck, mustParseCards(t, "HA SK"))
+	if err != nil {
+		t.Fatalf("remove cards: %v", err)
+	}
+	if len(rest) != 50 {
+		t.Fatalf("remove cards: got %d cards want 50", len(rest))
+	}
+}
+
 func TestEvaluate7Categories(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -92,17 +141,1013 @@ func TestEvaluate7Categories(t *testing.T) {
 	}
 }
 
+func TestScoreNMatchesBestOfFive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		n := 6 + i%4
+		deck := NewDeck()
+		rng.Shuffle(len(deck), func(a, b int) { deck[a], deck[b] = deck[b], deck[a] })
+		hand := deck[:n]
+
+		var best Score
+		for pick := 0; pick < 1<<n; pick++ {
+			if bits.OnesCount(uint(pick)) != 5 {
+				continue
+			}
+			five := make([]Card, 0, 5)
+			for k, c := range hand {
+				if pick&(1<<k) != 0 {
+					five = append(five, c)
+				}
+			}
+			if s, _ := scoreCards(five); s > best {
+				best = s
+			}
+		}
+
+		got, err := ScoreN(hand)
+		if err != nil {
+			t.Fatalf("score: %v", err)
+		}
+		if got != best {
+			t.Fatalf("%v: got %v want %v", hand, got, best)
+		}
+		rank, _ := EvaluateN(hand)
+		if r5 := Evaluate5(rank.Best5); Compare(r5, rank) != 0 {
+			t.Fatalf("%v: best five %v does not reproduce rank", hand, rank.Best5)
+		}
+	}
+}
+
+func TestEvaluateNSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cards    string
+		category Category
+	}{
+		{"flop pair", "HA SA C7 D9 S2", OnePair},
+		{"turn straight", "H9 CT HJ DQ C2 CK", Straight},
+		{"full house beats flush", "H2 H5 H7 HJ HK C7 D7 S2 C3", FullHouse},
+	}
+	for _, tc := range tests {
+		rank, err := EvaluateN(mustParseCards(t, tc.cards))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if rank.Category != tc.category {
+			t.Fatalf("%s: got %v want %v", tc.name, rank.Category, tc.category)
+		}
+	}
+	if _, err := EvaluateN(mustParseCards(t, "HA SA C7 D9")); err == nil {
+		t.Fatalf("expected hand size error")
+	}
+}
+
+func TestScore7DoesNotAllocate(t *testing.T) {
+	hand, err := ParseCards([]string{"H9", "HT", "HJ", "HQ", "HK", "C2", "D3"})
+	if err != nil {
+		t.Fatalf("parse cards: %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = Score7(hand)
+	})
+	if allocs != 0 {
+		t.Fatalf("Score7 allocated %v times per run", allocs)
+	}
+}
+
+func TestStrengthClasses(t *testing.T) {
+	if len(standardTables.classScores) != NumStrengthClasses {
+		t.Fatalf("classes: got %d want %d", len(standardTables.classScores), NumStrengthClasses)
+	}
+	tests := []struct {
+		class int
+		want  string
+	}{
+		{1, "Seven-high, 5-4-3-2 kicker"},
+		{NumStrengthClasses, "Royal flush"},
+	}
+	for _, tc := range tests {
+		got, err := DescribeStrength(tc.class)
+		if err != nil {
+			t.Fatalf("describe %d: %v", tc.class, err)
+		}
+		if got != tc.want {
+			t.Fatalf("describe %d: got %q want %q", tc.class, got, tc.want)
+		}
+	}
+	if _, err := DescribeStrength(0); err == nil {
+		t.Fatalf("expected out of range error")
+	}
+
+	weak, _ := Evaluate7(mustParseCards(t, "HA HK H9 H7 H3 C2 D2"))
+	strong, _ := Evaluate7(mustParseCards(t, "HA HK H9 H7 H4 C2 D2"))
+	if got := weak.Description(); got != "Ace-high flush, K-9-7-3 kicker" {
+		t.Fatalf("description: got %q", got)
+	}
+	if weak.Strength >= strong.Strength {
+		t.Fatalf("strength: %d should be below %d", weak.Strength, strong.Strength)
+	}
+}
+
+func TestEvaluateOmaha(t *testing.T) {
+	tests := []struct {
+		name     string
+		hole     string
+		board    string
+		category Category
+	}{
+		{"board flush needs two suited hole cards", "HA C9 D8 S2", "H2 H5 H7 HJ SK", OnePair},
+		{"two suited hole cards make the flush", "HA H9 D8 S2", "H2 H5 H7 CJ SK", Flush},
+		{"four of a kind in hand plays as a pair", "SA HA DA CA", "H2 C5 D7 CJ SK", OnePair},
+		{"board trips need a hole pair", "S9 H9 DK C2", "D9 C5 H5 CJ SK", FullHouse},
+	}
+	for _, tc := range tests {
+		rank, err := EvaluateOmaha(mustParseCards(t, tc.hole), mustParseCards(t, tc.board))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if rank.Category != tc.category {
+			t.Fatalf("%s: got %v want %v", tc.name, rank.Category, tc.category)
+		}
+	}
+	if _, err := EvaluateOmaha(mustParseCards(t, "HA H9"), mustParseCards(t, "H2 H5 H7")); err == nil {
+		t.Fatalf("expected hole size error")
+	}
+	if _, err := MonteCarloVariant(Omaha, mustParseCards(t, "HA SA HK SK"), nil, 2, 200); err != nil {
+		t.Fatalf("omaha monte carlo: %v", err)
+	}
+	if _, err := MonteCarloVariant(Omaha, mustParseCards(t, "HA SA HK SK"), nil, 12, 200); err == nil {
+		t.Fatalf("expected not enough cards error")
+	}
+}
+
+func TestShortDeck(t *testing.T) {
+	if got := len(NewShortDeck()); got != 36 {
+		t.Fatalf("short deck size: got %d want 36", got)
+	}
+
+	flush, err := EvaluateShortDeck(mustParseCards(t, "H6 H8 HT HQ HA"))
+	if err != nil {
+		t.Fatalf("evaluate flush: %v", err)
+	}
+	boat, err := EvaluateShortDeck(mustParseCards(t, "S9 H9 D9 CK HK"))
+	if err != nil {
+		t.Fatalf("evaluate full house: %v", err)
+	}
+	if Compare(flush, boat) <= 0 {
+		t.Fatalf("short deck flush should beat a full house")
+	}
+
+	wheel, err := EvaluateShortDeck(mustParseCards(t, "HA C6 D7 S8 H9 CK"))
+	if err != nil {
+		t.Fatalf("evaluate wheel: %v", err)
+	}
+	if wheel.Category != Straight || wheel.Tiebreak[0] != 9 {
+		t.Fatalf("wheel: got %v %v", wheel.Category, wheel.Tiebreak)
+	}
+	if len(wheel.Best5) != 5 {
+		t.Fatalf("wheel best five: got %v", wheel.Best5)
+	}
+
+	if _, err := EvaluateShortDeck(mustParseCards(t, "H2 H8 HT HQ HA")); err == nil {
+		t.Fatalf("expected card not in deck error")
+	}
+	if _, err := MonteCarloVariant(ShortDeckHoldem, mustParseCards(t, "HA SA"), nil, 2, 200); err != nil {
+		t.Fatalf("short deck monte carlo: %v", err)
+	}
+}
+
+func TestLowball(t *testing.T) {
+	tests := []struct {
+		name   string
+		better string
+		worse  string
+		eval   func([]Card) (HandRank, error)
+	}{
+		{"a-5 wheel is the nuts", "HA C2 D3 S4 H5", "HA C2 D3 S4 H6", EvaluateAceToFive},
+		{"a-5 ignores flushes", "H7 H5 H4 H3 H2", "C8 D5 S4 H3 H2", EvaluateAceToFive},
+		{"a-5 pairs lose", "HK CQ DJ S9 H8", "HA CA D2 S3 H4", EvaluateAceToFive},
+		{"a-5 best five of seven", "HA C2 D3 S4 H5 CK DK", "HA C2 D3 S4 H6 C7 D8", EvaluateAceToFive},
+		{"2-7 seven-five is the nuts", "H7 C5 D4 S3 H2", "H7 C6 D4 S3 H2", EvaluateDeuceToSeven},
+		{"2-7 ace plays high", "HK C5 D4 S3 H2", "HA C5 D4 S3 H2", EvaluateDeuceToSeven},
+		{"2-7 straights count", "H8 C6 D4 S3 H2", "H6 C5 D4 S3 H2", EvaluateDeuceToSeven},
+		{"2-7 flushes count", "H8 C6 D4 S3 H2", "H7 H5 H4 H3 H2", EvaluateDeuceToSeven},
+	}
+	for _, tc := range tests {
+		better, err := tc.eval(mustParseCards(t, tc.better))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		worse, err := tc.eval(mustParseCards(t, tc.worse))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if Compare(better, worse) <= 0 {
+			t.Fatalf("%s: %v should beat %v", tc.name, better.Best5, worse.Best5)
+		}
+	}
+
+	wheel, _ := EvaluateAceToFive(mustParseCards(t, "HA C2 D3 S4 H5"))
+	if got := wheel.Description(); got != "5-4-3-2-A low" {
+		t.Fatalf("description: got %q", got)
+	}
+	if wheel.Strength != len(aceToFiveTables.classScores) {
+		t.Fatalf("wheel strength: got %d want %d", wheel.Strength, len(aceToFiveTables.classScores))
+	}
+}
+
+func TestHiLo(t *testing.T) {
+	low, ok, err := EvaluateOmahaLow(mustParseCards(t, "HA C2 DK SK"), mustParseCards(t, "H3 C5 D8 SQ HJ"))
+	if err != nil || !ok {
+		t.Fatalf("expected qualifying low, got ok=%v err=%v", ok, err)
+	}
+	if got := low.Description(); got != "8-5-3-2-A low" {
+		t.Fatalf("low description: got %q", got)
+	}
+	if _, ok, _ := EvaluateOmahaLow(mustParseCards(t, "HA C2 DK SK"), mustParseCards(t, "H3 C9 DT SQ HJ")); ok {
+		t.Fatalf("a board with two low cards cannot make a low")
+	}
+	if _, ok, _ := EvaluateStudLow(mustParseCards(t, "HA C2 D3 S4 H9 C9 DK")); ok {
+		t.Fatalf("a nine-low should not qualify")
+	}
+
+	hi := aceToFiveTables.makeScore(HighCard, 7, 5, 4, 3, 2)
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"scoop with no low", potShare(10, 10, 1, 0, 0, 0), 1},
+		{"high only", potShare(10, 10, 1, 0, hi, 1), 0.5},
+		{"scoop both halves", potShare(10, 10, 1, hi, hi, 1), 1},
+		{"quartered low", potShare(5, 10, 1, hi, hi, 2), 0.25},
+		{"shared high, no low", potShare(10, 10, 2, 0, 0, 0), 0.5},
+	}
+	for _, tc := range tests {
+		if tc.got != tc.want {
+			t.Fatalf("%s: got %v want %v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	if _, err := MonteCarloVariant(OmahaHiLo, mustParseCards(t, "HA C2 D3 SK"), nil, 3, 200); err != nil {
+		t.Fatalf("omaha hi-lo monte carlo: %v", err)
+	}
+	if _, err := MonteCarloVariant(StudHiLo, mustParseCards(t, "HA C2 D3"), nil, 6, 200); err != nil {
+		t.Fatalf("stud hi-lo monte carlo: %v", err)
+	}
+	if _, err := MonteCarloVariant(StudHiLo, mustParseCards(t, "HA C2 D3"), nil, 8, 200); err == nil {
+		t.Fatalf("expected not enough cards error")
+	}
+	// partial stud hands are dealt out in equity but cannot be evaluated
+	partial := mustParseCards(t, "HA C2 D3 S4")
+	if _, err := Evaluate(StudHiLo, partial, nil); err == nil || !strings.Contains(err.Error(), "5 to 7") {
+		t.Fatalf("expected stud evaluate error, got %v", err)
+	}
+	if _, _, err := EvaluateLow(StudHiLo, partial, nil); err == nil {
+		t.Fatalf("expected stud low error")
+	}
+}
+
+func TestWildCards(t *testing.T) {
+	deuces, err := WildRank('2')
+	if err != nil || deuces.Count() != 4 {
+		t.Fatalf("wild rank: %v %v", deuces, err)
+	}
+	tests := []struct {
+		name     string
+		cards    string
+		wild     CardSet
+		want     Category
+		playedAs string
+	}{
+		{"joker completes a flush", "HA HK H9 H4 XJ C2 D7", 0, Flush, "HA HK H9 H4 HQ"},
+		{"joker fills a straight flush", "S9 S8 S7 S5 XJ", 0, StraightFlush, "S9 S8 S7 S5 S6"},
+		{"joker makes five of a kind", "HA SA DA CA XJ CK D2", 0, FiveOfAKind, "HA SA DA CA SA"},
+		{"deuces make five of a kind", "HK SK C2 D2 XJ", deuces, FiveOfAKind, "HK SK DK CK SK"},
+		{"no wilds evaluates naturally", "HA SA DK C7 H3", deuces, OnePair, ""},
+	}
+	for _, tc := range tests {
+		rank, err := EvaluateWild(mustParseWildCards(t, tc.cards), tc.wild)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if rank.Category != tc.want {
+			t.Fatalf("%s: got %s want %v", tc.name, rank.Name(), tc.want)
+		}
+		var played []string
+		for _, c := range rank.PlayedAs {
+			played = append(played, c.String())
+		}
+		if got := strings.Join(played, " "); got != tc.playedAs {
+			t.Fatalf("%s: played as %q want %q", tc.name, got, tc.playedAs)
+		}
+	}
+
+	five, _ := EvaluateWild(mustParseWildCards(t, "H2 C2 XJ S9 D9"), deuces)
+	straightFlush := Evaluate5(mustParseCards(t, "HA HK HQ HJ HT"))
+	if Compare(five, straightFlush) <= 0 {
+		t.Fatalf("five of a kind should beat a royal flush")
+	}
+	if five.Strength != NumStrengthClasses+8 || five.Strength <= straightFlush.Strength {
+		t.Fatalf("five nines strength: got %d", five.Strength)
+	}
+	if got := five.Description(); got != "Five Nines" {
+		t.Fatalf("description: got %q", got)
+	}
+	if _, err := Evaluate(Holdem, mustParseWildCards(t, "XJ HA"), mustParseCards(t, "C2 D3 S4")); err == nil {
+		t.Fatalf("expected error for joker outside EvaluateWild")
+	}
+	if _, err := ParseCards([]string{"HA", "XJ"}); err == nil {
+		t.Fatalf("expected ParseCards to reject the joker")
+	}
+}
+
+func mustParseWildCards(t *testing.T, s string) []Card {
+	t.Helper()
+	cards, err := ParseWildCards(strings.Fields(s))
+	if err != nil {
+		t.Fatalf("parse cards %q: %v", s, err)
+	}
+	return cards
+}
+
+func TestExactEquity(t *testing.T) {
+	// the board plays for everyone, so every pot is split
+	res, err := Exact(Holdem, mustParseCards(t, "C2 D3"), mustParseCards(t, "HA HK HQ HJ HT"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Equity-1.0/3) > 1e-9 || res.Tie != 1 || res.Simulations != 45*44/2*43*42/2 {
+		t.Fatalf("split board: got %+v", res)
+	}
+
+	hole, board := mustParseCards(t, "HA SA"), mustParseCards(t, "C7 D8 S2")
+	exact, err := Exact(Holdem, hole, board, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(exact.Win+exact.Tie+exact.Loss-1) > 1e-9 || exact.Win < exact.Equity-exact.Tie {
+		t.Fatalf("inconsistent breakdown: %+v", exact)
+	}
+	sampled, err := MonteCarlo(hole, board, 2, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(exact.Equity-sampled.Equity) > 0.02 || sampled.Simulations != 20000 {
+		t.Fatalf("exact %+v and sampled %+v disagree", exact, sampled)
+	}
+	if exact.StdErr != 0 || exact.CILow != exact.Equity || sampled.StdErr <= 0 || sampled.CILow > exact.Equity+0.01 || sampled.CIHigh < exact.Equity-0.01 {
+		t.Fatalf("confidence intervals: exact %+v sampled %+v", exact, sampled)
+	}
+	precise, err := MonteCarloWithOptions(Holdem, hole, board, 2, MonteCarloOptions{Simulations: 1000000, Precision: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if precise.Simulations >= 1000000 || precise.CIHigh-precise.CILow > 0.02+1e-9 {
+		t.Fatalf("target precision did not stop early: %+v", precise)
+	}
+
+	if _, err := Exact(Holdem, hole, nil, 2); err == nil {
+		t.Fatalf("expected error enumerating a preflop deal")
+	}
+	if res, err := Equity(context.Background(), Holdem, MethodAuto, hole, board, 2, MonteCarloOptions{Simulations: 100}); err != nil || res[0].Method != MethodExact {
+		t.Fatalf("auto on the flop: got %+v, %v", res, err)
+	}
+	if res, err := Equity(context.Background(), Holdem, MethodAuto, hole, nil, 2, MonteCarloOptions{Simulations: 100}); err != nil || res[0].Method != MethodMonteCarlo {
+		t.Fatalf("auto preflop: got %+v, %v", res, err)
+	}
+	// enumeration waits for a slot in the pool like a simulation does
+	pool := NewWorkerPool(1)
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	waiting, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	_, err = Equity(waiting, Holdem, MethodExact, hole, board, 2, MonteCarloOptions{Pool: pool})
+	cancel()
+	pool.release()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("exact with a full pool: got %v", err)
+	}
+	// the limit counts work, so five-card Omaha hi-lo samples even on the river
+	plo5, river := mustParseCards(t, "HA H2 C3 DK SQ"), mustParseCards(t, "D4 S5 C9 HT CJ")
+	if res, err := Equity(context.Background(), OmahaHiLo, MethodAuto, plo5, river, 2, MonteCarloOptions{Simulations: 100}); err != nil || res[0].Method != MethodMonteCarlo {
+		t.Fatalf("auto omaha8 river: got %+v, %v", res, err)
+	}
+	if _, err := Exact(OmahaHiLo, plo5, river, 2); err == nil {
+		t.Fatalf("expected error enumerating a five-card omaha8 river")
+	}
+	if _, err := ParseMethod("guess"); err == nil {
+		t.Fatalf("expected error for unknown method")
+	}
+}
+
+func TestMonteCarloWorkers(t *testing.T) {
+	hole, board := mustParseCards(t, "HA SA"), mustParseCards(t, "C7 D8 S2")
+	pool := NewWorkerPool(2)
+	res, err := MonteCarloWithOptions(Holdem, hole, board, 2, MonteCarloOptions{Simulations: 10001, Workers: 4, Pool: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Simulations != 10001 {
+		t.Fatalf("simulations: got %d want 10001", res.Simulations)
+	}
+	exact, err := Exact(Holdem, hole, board, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Equity-exact.Equity) > 0.03 {
+		t.Fatalf("parallel %v and exact %v disagree", res.Equity, exact.Equity)
+	}
+
+	// a seeded run is reproducible whatever the number of workers
+	seed := int64(42)
+	one, err := MonteCarloWithOptions(Holdem, hole, board, 3, MonteCarloOptions{Simulations: 5500, Workers: 1, Seed: &seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	many, err := MonteCarloWithOptions(Holdem, hole, board, 3, MonteCarloOptions{Simulations: 5500, Workers: 4, Pool: pool, Seed: &seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(one, many) || one.Seed != seed {
+		t.Fatalf("seeded runs differ: %+v vs %+v", one, many)
+	}
+
+	// a time budget returns what was reached; cancellation is an error
+	budget, err := MonteCarloWithOptions(Holdem, hole, board, 2, MonteCarloOptions{Simulations: 1 << 30, TimeBudget: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !budget.TimedOut || budget.Simulations == 0 || budget.Simulations >= 1<<30 {
+		t.Fatalf("time budget: got %+v", budget)
+	}
+	if tiny, err := MonteCarloWithOptions(Holdem, hole, board, 2, MonteCarloOptions{Simulations: 1 << 30, TimeBudget: time.Nanosecond}); err != nil || !tiny.TimedOut {
+		t.Fatalf("budget too small for a trial: got %+v, %v", tiny, err)
+	}
+	// auto keeps to the budget when enumeration would run past it
+	auto, err := Equity(context.Background(), Holdem, MethodAuto, hole, board, 2, MonteCarloOptions{TimeBudget: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auto[0].Method != MethodMonteCarlo || !auto[0].TimedOut || auto[0].Simulations == 0 {
+		t.Fatalf("auto with a time budget: got %+v", auto[0])
+	}
+	// a budget without a trial count samples until it runs out, however the
+	// simulation was chosen
+	for _, m := range []Method{MethodAuto, MethodMonteCarlo} {
+		res, err := Equity(context.Background(), Holdem, m, hole, nil, 2, MonteCarloOptions{TimeBudget: 20 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("%s preflop with a time budget: %v", m, err)
+		}
+		if !res[0].TimedOut || res[0].Simulations == 0 {
+			t.Fatalf("%s preflop with a time budget: got %+v", m, res[0])
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := MonteCarloContext(ctx, Holdem, hole, board, 2, MonteCarloOptions{Simulations: 100000}); err != context.Canceled {
+		t.Fatalf("cancelled simulation: got %v", err)
+	}
+	if _, err := ExactContext(ctx, Holdem, hole, board, 2); err != context.Canceled {
+		t.Fatalf("cancelled enumeration: got %v", err)
+	}
+	if _, err := MonteCarloWithOptions(Holdem, hole, board, 2, MonteCarloOptions{Simulations: 10, Workers: -1}); err == nil {
+		t.Fatalf("expected error for negative workers")
+	}
+}
+
+func TestEquityHands(t *testing.T) {
+	hands := [][]Card{mustParseCards(t, "HA HK"), mustParseCards(t, "SQ CQ"), mustParseCards(t, "D7 D8")}
+	board := mustParseCards(t, "HQ H7 C2")
+	res, err := EquityHands(context.Background(), Holdem, MethodAuto, hands, board, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 || res[0].Method != MethodExact || res[0].Simulations != 43*42/2 {
+		t.Fatalf("got %+v", res)
+	}
+	total := 0.0
+	for _, r := range res {
+		total += r.Equity
+	}
+	if math.Abs(total-1) > 1e-9 || res[1].Equity < res[0].Equity || res[1].Equity < res[2].Equity {
+		t.Fatalf("equities: %v %v %v", res[0].Equity, res[1].Equity, res[2].Equity)
+	}
+
+	// a random hand matches the hero-only calculation
+	hole := mustParseCards(t, "HA SA")
+	withRandom, err := EquityHands(context.Background(), Holdem, MethodExact, [][]Card{hole, nil}, board, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	heroOnly, err := Exact(Holdem, hole, board, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(withRandom[0], heroOnly) {
+		t.Fatalf("random opponent: %+v vs %+v", withRandom[0], heroOnly)
+	}
+
+	if _, err := EquityHands(context.Background(), Holdem, MethodAuto, [][]Card{nil, nil}, board, MonteCarloOptions{}); err == nil {
+		t.Fatalf("expected error with no known hands")
+	}
+	omaha := [][]Card{mustParseCards(t, "HA SA HK SK"), mustParseCards(t, "C2 C3 C4 C5 C6")}
+	if _, err := EquityHands(context.Background(), Omaha, MethodAuto, omaha, board, MonteCarloOptions{}); err == nil {
+		t.Fatalf("expected error for mixed omaha hand sizes")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec   string
+		combos int
+	}{
+		{"QQ+", 18},
+		{"22-55", 24},
+		{"AKs", 4},
+		{"AKo", 12},
+		{"AK", 16},
+		{"AJo-ATo", 24},
+		{"76s", 4},
+		{"KTs+", 12},
+		{"AhKh", 1},
+		{"HASK", 1},
+		{"random", 1326},
+		{"QQ+, AKs, AJo-ATo, 76s, KTs+, AhKh", 18 + 4 + 24 + 4 + 12},
+		{"AK, AKs", 16},
+	}
+	for _, tc := range tests {
+		r, err := ParseRange(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if len(r) != tc.combos {
+			t.Fatalf("%s: got %d combos want %d", tc.spec, len(r), tc.combos)
+		}
+	}
+
+	r, err := ParseRange("AA, KK:0.5, KhKs:0.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.TotalWeight() != 6+5*0.5+0.25 {
+		t.Fatalf("weights: got %v", r.TotalWeight())
+	}
+	if got := len(r.Without(NewCardSet(mustParseCards(t, "HA SK")...))); got != 3+3 {
+		t.Fatalf("card removal: got %d combos", got)
+	}
+
+	for _, bad := range []string{"", "AKx", "AA+s", "AKs-QJs", "22-AKs", "AK:2", "1K", "AhAh"} {
+		if _, err := ParseRange(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestEquityRanges(t *testing.T) {
+	mustRange := func(spec string) Range {
+		r, err := ParseRange(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	seed := int64(3)
+	opts := MonteCarloOptions{Simulations: 20000, Seed: &seed}
+	res, err := EquityRanges(context.Background(), Holdem, MethodAuto, []Range{mustRange("AA"), mustRange("KK")}, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Method != MethodMonteCarlo || math.Abs(res[0].Equity-0.82) > 0.02 || math.Abs(res[0].Equity+res[1].Equity-1) > 1e-9 {
+		t.Fatalf("AA vs KK: %+v", res)
+	}
+
+	// the board removes every ace but one pair of aces from the range
+	board := mustParseCards(t, "HA DA C7 D8 S2")
+	res, err = EquityRanges(context.Background(), Holdem, MethodAuto, []Range{mustRange("AA"), mustRange("random")}, board, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Loss != 0 {
+		t.Fatalf("quad aces lost: %+v", res[0])
+	}
+
+	// overlapping ranges are never dealt the same card
+	res, err = EquityRanges(context.Background(), Holdem, MethodAuto, []Range{mustRange("AhAs, KK"), mustRange("AhAs, QQ")}, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Simulations != 20000 {
+		t.Fatalf("overlapping ranges: %+v", res[0])
+	}
+
+	// single-combo ranges are known hands and can be enumerated exactly
+	res, err = EquityRanges(context.Background(), Holdem, MethodExact, []Range{mustRange("AhKh"), mustRange("QsQc")}, board[2:], opts)
+	if err != nil || res[0].Method != MethodExact {
+		t.Fatalf("known combos: %+v, %v", res, err)
+	}
+	if _, err := EquityRanges(context.Background(), Holdem, MethodExact, []Range{mustRange("AA"), mustRange("KK")}, nil, opts); err == nil {
+		t.Fatalf("expected error enumerating ranges exactly")
+	}
+	if _, err := EquityRanges(context.Background(), Omaha, MethodAuto, []Range{mustRange("AA"), mustRange("KK")}, nil, opts); err == nil {
+		t.Fatalf("expected error for ranges in omaha")
+	}
+	if _, err := EquityRanges(context.Background(), Holdem, MethodAuto, []Range{mustRange("AhAs, AhAd"), mustRange("AhAc, AsAd")}, board[2:], opts); err == nil {
+		t.Fatalf("expected error for ranges that always collide")
+	}
+}
+
+func TestDeadCards(t *testing.T) {
+	hole, board := mustParseCards(t, "HK SK"), mustParseCards(t, "CA D9 C7 D4 S2")
+	live, err := Equity(context.Background(), Holdem, MethodExact, hole, board, 2, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MonteCarloOptions{Dead: mustParseCards(t, "DA HA SA")}
+	dead, err := Equity(context.Background(), Holdem, MethodExact, hole, board, 2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dead[0].Simulations != 42*41/2 || dead[0].Equity <= live[0].Equity {
+		t.Fatalf("dead aces: %+v vs %+v", dead[0], live[0])
+	}
+
+	opts.Simulations = 100
+	if _, err := MonteCarloContext(context.Background(), Holdem, hole, board, 2, opts); err != nil {
+		t.Fatal(err)
+	}
+	ace := mustParseCards(t, "DA")[0]
+	for _, bad := range [][]Card{mustParseCards(t, "HK"), mustParseCards(t, "C7"), {ace, ace}} {
+		opts.Dead = bad
+		if _, err := Equity(context.Background(), Holdem, MethodAuto, hole, board, 2, opts); err == nil {
+			t.Fatalf("expected error for dead cards %v", bad)
+		}
+	}
+	// a card in the hand and on the board is an impossible deal
+	opts.Dead = nil
+	if _, err := Equity(context.Background(), Holdem, MethodAuto, hole, append([]Card{hole[0]}, board[1:]...), 2, opts); err == nil {
+		t.Fatalf("expected error for a hole card on the board")
+	}
+	if _, err := EquityHands(context.Background(), Holdem, MethodAuto, [][]Card{hole, {hole[1], ace}}, board, opts); err == nil {
+		t.Fatalf("expected error for a card in two hands")
+	}
+}
+
+func TestCategoryDistribution(t *testing.T) {
+	res, err := Exact(Holdem, mustParseCards(t, "HA HK"), mustParseCards(t, "H2 H7 HQ C3 D4"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Categories[Flush] != 1 || len(res.Categories) != 1 {
+		t.Fatalf("hero categories: %v", res.Categories)
+	}
+	total := 0.0
+	for _, p := range res.OpponentCategories {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 || res.OpponentCategories[HighCard] == 0 || res.OpponentCategories[Flush] == 0 {
+		t.Fatalf("opponent categories: %v", res.OpponentCategories)
+	}
+
+	// the best opponent of three is never worse than a single one
+	sims := MonteCarloOptions{Simulations: 20000}
+	one, err := MonteCarloWithOptions(Holdem, mustParseCards(t, "C2 D7"), nil, 2, sims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	three, err := MonteCarloWithOptions(Holdem, mustParseCards(t, "C2 D7"), nil, 4, sims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if three.OpponentCategories[HighCard] >= one.OpponentCategories[HighCard] {
+		t.Fatalf("high card for the best of three opponents: %v vs %v", three.OpponentCategories[HighCard], one.OpponentCategories[HighCard])
+	}
+}
+
+func TestOuts(t *testing.T) {
+	hole, board := mustParseCards(t, "HA HK"), mustParseCards(t, "H2 H7 C9")
+	queens := Range{{Cards: [2]Card(mustParseCards(t, "SQ CQ")), Weight: 1}}
+	res, err := Outs(Holdem, hole, board, queens, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Count != 15 || res.ByCategory[Flush] != 9 || res.ByCategory[OnePair] != 6 || res.Unseen != 45 {
+		t.Fatalf("flush draw and overcards: %+v", res)
+	}
+	if math.Abs(res.TurnProbability-15.0/45) > 1e-9 || math.Abs(res.RiverProbability-(1-30.0*29/(45*44))) > 1e-9 {
+		t.Fatalf("probabilities: %v %v", res.TurnProbability, res.RiverProbability)
+	}
+
+	// against a range, an out counts for the share of combos it turns around
+	r, err := ParseRange("QQ, 99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = Outs(Holdem, hole, mustParseCards(t, "H2 H7 C9 DQ"), r, mustParseCards(t, "SQ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range res.Outs {
+		if out.Category != Flush || out.Weight != 1 {
+			t.Fatalf("river outs against sets: %+v", res.Outs)
+		}
+	}
+	if res.TurnProbability != 0 || res.RiverProbability != res.Count/float64(res.Unseen) {
+		t.Fatalf("turn probabilities: %+v", res)
+	}
+
+	ahead, err := Outs(Holdem, hole, board, Range{{Cards: [2]Card(mustParseCards(t, "C3 D4")), Weight: 1}}, nil)
+	if err != nil || len(ahead.Outs) != 0 {
+		t.Fatalf("hero already ahead: %+v, %v", ahead, err)
+	}
+	if _, err := Outs(Holdem, hole, mustParseCards(t, "H2 H7 C9 D3 S4"), queens, nil); err == nil {
+		t.Fatalf("expected error for a complete board")
+	}
+}
+
+func TestAnalyzeBoard(t *testing.T) {
+	cases := []struct {
+		board string
+		want  BoardTexture
+	}{
+		{"H9 HT C2", BoardTexture{TwoTone: true, Connected: true, Wet: true}},
+		{"H9 CT D7", BoardTexture{Rainbow: true, Connected: true, StraightPossible: true, Wet: true}},
+		{"HK D7 C2", BoardTexture{Rainbow: true}},
+		{"SA S2 S3", BoardTexture{Monotone: true, Connected: true, StraightPossible: true, FlushPossible: true, Wet: true}},
+		{"D8 C8 H8", BoardTexture{Rainbow: true, Paired: true, Trips: true}},
+		{"D8 H8 HK D3", BoardTexture{TwoTone: true, Paired: true}},
+	}
+	for _, tc := range cases {
+		got, err := AnalyzeBoard(mustParseCards(t, tc.board))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.board, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.board, got, tc.want)
+		}
+	}
+	if _, err := AnalyzeBoard(mustParseCards(t, "H9 HT")); err == nil {
+		t.Fatalf("expected error for a two-card board")
+	}
+}
+
+func TestAnalyzeDraws(t *testing.T) {
+	cases := []struct {
+		hole, board string
+		want        Draws
+	}{
+		{"H9 HT", "H8 S7 C2", Draws{OpenEnded: true, StraightRanks: 2, BackdoorFlush: true, Overcards: 2}},
+		{"HA HK", "H2 H7 C9", Draws{FlushDraw: true, Overcards: 2}},
+		{"SJ D9", "H8 C7 D2", Draws{Gutshot: true, StraightRanks: 1, Overcards: 2}},
+		{"SQ DJ", "HT C4 D2", Draws{BackdoorStraight: true, Overcards: 2}},
+		// the eight only completes the board's straight, the king also uses the ace
+		{"SA D3", "H9 CT DJ SQ", Draws{Gutshot: true, StraightRanks: 1, Overcards: 1}},
+		// a made straight and flush are not draws
+		{"H9 HT", "H8 H7 H6", Draws{Overcards: 2}},
+		// an overpair holds no overcards
+		{"SK DK", "H8 C5 D2", Draws{}},
+	}
+	for _, tc := range cases {
+		got, err := AnalyzeDraws(mustParseCards(t, tc.hole), mustParseCards(t, tc.board))
+		if err != nil {
+			t.Fatalf("%s on %s: %v", tc.hole, tc.board, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s on %s: got %+v, want %+v", tc.hole, tc.board, got, tc.want)
+		}
+	}
+	if _, err := AnalyzeDraws(mustParseCards(t, "H9 HT"), mustParseCards(t, "H8 S7 C2 D3 D4")); err == nil {
+		t.Fatalf("expected error for a complete board")
+	}
+	if _, err := AnalyzeDraws([]Card{0, 1}, []Card{1, 2, 3}); err == nil {
+		t.Fatalf("expected error for repeated cards")
+	}
+}
+
+func TestEvaluateRelative(t *testing.T) {
+	res, err := EvaluateRelative(Holdem, mustParseCards(t, "HA HK"), mustParseCards(t, "H2 H7 H9"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Nuts || res.Behind != 0 || res.Ahead+res.Tied != 1081 || res.Percentile <= 0.99 {
+		t.Fatalf("nut flush: %+v", res)
+	}
+	if len(res.NutHands) != 1 || NewCardSet(res.NutHands[0][:]...) != NewCardSet(mustParseCards(t, "HA HK")...) {
+		t.Fatalf("nut hands: %v", res.NutHands)
+	}
+
+	res, err = EvaluateRelative(Holdem, mustParseCards(t, "C2 D3"), mustParseCards(t, "HA SK D9 C7 H5"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Nuts || res.Percentile > 0.2 || res.NutScore.Category() != Straight || len(res.NutHands) != 16 {
+		t.Fatalf("playing the board: %+v", res)
+	}
+	if want := (float64(res.Ahead) + float64(res.Tied)/2) / float64(res.Ahead+res.Tied+res.Behind); res.Percentile != want {
+		t.Fatalf("percentile %v, want %v", res.Percentile, want)
+	}
+
+	// with every eight dead nobody can hold the straight
+	dead := mustParseCards(t, "C8 D8 H8 S8")
+	res, err = EvaluateRelative(Holdem, mustParseCards(t, "C2 D3"), mustParseCards(t, "HA SK D9 C7 H5"), dead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Ahead+res.Tied+res.Behind != 41*40/2 || res.NutScore.Category() == Straight {
+		t.Fatalf("dead eights: %+v", res)
+	}
+	if _, err := EvaluateRelative(Holdem, mustParseCards(t, "C2 D3"), mustParseCards(t, "HA SK D9"), mustParseCards(t, "D3")); err == nil {
+		t.Fatalf("expected error for a dead hole card")
+	}
+
+	if _, err := EvaluateRelative(Omaha, mustParseCards(t, "HA HK SA SK"), mustParseCards(t, "H2 H7 H9"), nil); err == nil {
+		t.Fatalf("expected error for Omaha")
+	}
+	if _, err := EvaluateRelative(Holdem, mustParseCards(t, "HA HK"), mustParseCards(t, "HA H7 H9"), nil); err == nil {
+		t.Fatalf("expected error for repeated cards")
+	}
+}
+
+func TestRankShowdown(t *testing.T) {
+	hands := func(specs ...string) [][]Card {
+		out := make([][]Card, len(specs))
+		for i, spec := range specs {
+			out[i] = mustParseCards(t, spec)
+		}
+		return out
+	}
+	board := mustParseCards(t, "HA SK D9 C7 H5")
+	res, err := RankShowdown(Holdem, hands("C2 D3", "S2 H3", "CA D4", "C6 D8"), board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{3}, {2}, {0, 1}}; !reflect.DeepEqual(res.Order, want) {
+		t.Fatalf("order %v, want %v", res.Order, want)
+	}
+	if res.High[3].Category != Straight || res.Low != nil || res.LowOrder != nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	res, err = RankShowdown(OmahaHiLo, hands("C4 C5 DK DQ", "S4 S5 HK HQ", "SQ DJ C9 D9"), mustParseCards(t, "HA H2 D3 S9 CK"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{0, 1}, {2}}; !reflect.DeepEqual(res.Order, want) {
+		t.Fatalf("hi-lo order %v, want %v", res.Order, want)
+	}
+	if want := [][]int{{0, 1}}; !reflect.DeepEqual(res.LowOrder, want) || res.HasLow[2] {
+		t.Fatalf("low order %v, want %v", res.LowOrder, want)
+	}
+
+	if _, err := RankShowdown(Holdem, hands("C2 D3", "C2 H3"), board); err == nil {
+		t.Fatalf("expected error for a card held by two players")
+	}
+	if _, err := RankShowdown(Holdem, hands("C2 D3"), board); err == nil {
+		t.Fatalf("expected error for a single player")
+	}
+	if _, err := RankShowdown(Holdem, hands("C2 D3", "HA H3"), board); err == nil {
+		t.Fatalf("expected error for a card on the board and in a hand")
+	}
+	if _, err := RankShowdown(Holdem, hands("C2 D3", "S2 H3"), board[:3]); err == nil {
+		t.Fatalf("expected error for a flop-only board")
+	}
+	if _, err := RankShowdown(StudHiLo, hands("C2 D3 H4 S5 C6", "S2 H3 D4 C5 S6 H7 D8"), nil); err == nil {
+		t.Fatalf("expected error for a five-card stud hand")
+	}
+}
+
+func TestDistributePots(t *testing.T) {
+	board := mustParseCards(t, "HA SK D9 C7 H5")
+	high := func(hole string) HandRank {
+		h, err := Evaluate(Holdem, mustParseCards(t, hole), board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	// a short all-in wins the main pot, the side pot goes to the best caller
+	res, err := DistributePots([]PotPlayer{
+		{Committed: 100, High: high("C6 D8")},
+		{Committed: 300, High: high("CA D4")},
+		{Committed: 300, High: high("C2 D3")},
+		{Committed: 50, Folded: true},
+	}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{350, 400, 0, 0}; !reflect.DeepEqual(res.Payouts, want) {
+		t.Fatalf("payouts %v, want %v", res.Payouts, want)
+	}
+	if len(res.Pots) != 2 || res.Pots[0].Amount != 350 || !reflect.DeepEqual(res.Pots[1].Eligible, []int{1, 2}) {
+		t.Fatalf("pots %+v", res.Pots)
+	}
+
+	// the odd chip goes to the first winner left of the button
+	split := []PotPlayer{
+		{Committed: 5, High: high("C2 D3")},
+		{Committed: 5, High: high("S2 H3")},
+		{Committed: 5, Folded: true},
+	}
+	for button, want := range [][]int64{{7, 8, 0}, {8, 7, 0}, {8, 7, 0}} {
+		res, err := DistributePots(split, button)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(res.Payouts, want) {
+			t.Fatalf("button %d: payouts %v, want %v", button, res.Payouts, want)
+		}
+	}
+
+	// hi-lo pots split between the best high and the best low
+	hilo := mustParseCards(t, "HA H2 D3 S9 C9")
+	scoop, err := Evaluate(OmahaHiLo, mustParseCards(t, "DA CA SK DK"), hilo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wheelHigh, err := Evaluate(OmahaHiLo, mustParseCards(t, "C4 C5 DK DQ"), hilo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wheel, ok, err := EvaluateLow(OmahaHiLo, mustParseCards(t, "C4 C5 DK DQ"), hilo)
+	if err != nil || !ok {
+		t.Fatalf("expected a low: %v", err)
+	}
+	res, err = DistributePots([]PotPlayer{
+		{Committed: 51, High: wheelHigh, Low: wheel, HasLow: true},
+		{Committed: 51, High: scoop},
+		{Committed: 1, Folded: true},
+	}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{51, 52, 0}; !reflect.DeepEqual(res.Payouts, want) {
+		t.Fatalf("hi-lo payouts %v, want %v", res.Payouts, want)
+	}
+
+	if _, err := DistributePots([]PotPlayer{{Committed: 5, Folded: true}, {Committed: 5, Folded: true}}, 0); err == nil {
+		t.Fatalf("expected error when everyone folded")
+	}
+	if _, err := DistributePots([]PotPlayer{{Committed: math.MaxInt64, High: high("C2 D3")}, {Committed: math.MaxInt64, High: high("S2 H3")}}, 0); err == nil {
+		t.Fatalf("expected error for chips that overflow")
+	}
+	if _, err := DistributePots(split, 3); err == nil {
+		t.Fatalf("expected error for a button off the table")
+	}
+}
+
+func TestEvaluate5Variant(t *testing.T) {
+	flush := mustParseCards(t, "H6 H8 H9 HJ HA")
+	boat := mustParseCards(t, "SK HK DK S7 C7")
+	if Compare(Evaluate5(flush), Evaluate5(boat)) != -1 {
+		t.Fatal("standard rules: full house should beat a flush")
+	}
+	sdFlush, sdBoat := Evaluate5(flush, ShortDeckHoldem), Evaluate5(boat, ShortDeckHoldem)
+	if sdFlush.Category != Flush || sdBoat.Category != FullHouse {
+		t.Fatalf("categories = %v, %v", sdFlush.Category, sdBoat.Category)
+	}
+	if Compare(sdFlush, sdBoat) != 1 {
+		t.Fatal("short deck: flush should beat a full house")
+	}
+	if r := Evaluate5(mustParseCards(t, "H2 H8 H9 HJ HA"), ShortDeckHoldem); r.Score != 0 {
+		t.Fatal("a deuce should not rank in short deck")
+	}
+	if r := Evaluate5(flush, Variant(99)); r.Score != 0 {
+		t.Fatal("an unknown variant should not rank")
+	}
+}
+
+func TestEquitySeats(t *testing.T) {
+	hole, board := mustParseCards(t, "HA SA"), mustParseCards(t, "C7 D8 S2 HK C3")
+	res, err := Equity(context.Background(), Holdem, MethodExact, hole, board, 3, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hands, err := EquityHands(context.Background(), Holdem, MethodExact, [][]Card{hole, nil, nil}, board, MonteCarloOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 || !reflect.DeepEqual(res, hands) {
+		t.Fatalf("seats: got %+v want %+v", res, hands)
+	}
+	// the opponents are dealt alike, so they fare alike
+	if math.Abs(res[1].Equity-res[2].Equity) > 1e-9 || math.Abs(res[0].Equity+res[1].Equity+res[2].Equity-1) > 1e-9 {
+		t.Fatalf("opponent equities: %+v", res)
+	}
+}
+
 func TestMonteCarloValidation(t *testing.T) {
 	if _, err := MonteCarlo([]Card{}, []Card{}, 2, 100); err == nil {
 		t.Fatalf("expected hole size error")
 	}
-	if _, err := MonteCarlo([]Card{{Suit: 'H', Rank: 'A'}, {Suit: 'S', Rank: 'K'}}, []Card{}, 1, 100); err == nil {
+	if _, err := MonteCarlo(mustParseCards(t, "HA SK"), []Card{}, 1, 100); err == nil {
 		t.Fatalf("expected players error")
 	}
-	if _, err := MonteCarlo([]Card{{Suit: 'H', Rank: 'A'}, {Suit: 'S', Rank: 'K'}}, []Card{}, 2, 0); err == nil {
+	if _, err := MonteCarlo(mustParseCards(t, "HA SK"), []Card{}, 2, 0); err == nil {
 		t.Fatalf("expected simulations error")
 	}
-	if _, err := MonteCarlo([]Card{{Suit: 'H', Rank: 'A'}, {Suit: 'S', Rank: 'K'}}, []Card{{Suit: 'C', Rank: '2'}}, 2, 100); err == nil {
+	if _, err := MonteCarlo(mustParseCards(t, "HA SK"), mustParseCards(t, "C2"), 2, 100); err == nil {
 		t.Fatalf("expected community size error")
 	}
 }
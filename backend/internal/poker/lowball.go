@@ -0,0 +1,65 @@
+package poker
+
+// EvaluateAceToFive computes the best ace-to-five low from 5 to 9 cards. The
+// ace plays low, straights and flushes are ignored, and 5-4-3-2-A is the best
+// hand. Tiebreak ranks count the ace as 1, and a better low compares higher.
+func EvaluateAceToFive(cs []Card) (HandRank, error) {
+	return evaluateLow(aceToFiveTables, cs)
+}
+
+// ScoreAceToFive scores an ace-to-five low without allocating.
+func ScoreAceToFive(cs []Card) (Score, error) {
+	score, _, err := scoreLow(aceToFiveTables, cs)
+	return score, err
+}
+
+// EvaluateDeuceToSeven computes the best deuce-to-seven low from 5 to 9 cards.
+// The ace plays high, straights and flushes count against the hand, and
+// 7-5-4-3-2 unsuited is the best hand. A better low compares higher.
+func EvaluateDeuceToSeven(cs []Card) (HandRank, error) {
+	return evaluateLow(deuceToSevenTables, cs)
+}
+
+// ScoreDeuceToSeven scores a deuce-to-seven low without allocating.
+func ScoreDeuceToSeven(cs []Card) (Score, error) {
+	score, _, err := scoreLow(deuceToSevenTables, cs)
+	return score, err
+}
+
+func evaluateLow(t *rankTables, cs []Card) (HandRank, error) {
+	score, best, err := scoreLow(t, cs)
+	if err != nil {
+		return HandRank{}, err
+	}
+	return newHandRank(score, append([]Card{}, best[:]...)), nil
+}
+
+// scores every five-card subset with the low tables and keeps the best.
+func scoreLow(t *rankTables, cs []Card) (Score, [5]Card, error) {
+	var best, five [5]Card
+	n := len(cs)
+	if n < minEvalCards || n > maxEvalCards {
+		return 0, best, errHandSize
+	}
+	var bestScore Score
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for c := b + 1; c < n; c++ {
+				for d := c + 1; d < n; d++ {
+					for e := d + 1; e < n; e++ {
+						five = [5]Card{cs[a], cs[b], cs[c], cs[d], cs[e]}
+						s, err := t.score(five[:])
+						if err != nil {
+							return 0, best, err
+						}
+						if s > bestScore {
+							bestScore = s
+							best = five
+						}
+					}
+				}
+			}
+		}
+	}
+	return bestScore, best, nil
+}
@@ -1,87 +1,428 @@
 package poker
 
 import (
+	"context"
 	"errors"
+	"math"
 	"math/rand"
+	"runtime"
+	"sync"
 	"time"
 )
 
-// MonteCarlo estimates win probability for the given hole cards and community.
-// The probability accounts for ties by splitting the pot evenly.
-func MonteCarlo(hole []Card, community []Card, players int, sims int) (float64, error) {
-	if len(hole) != 2 {
-		return 0, errors.New("hole must have 2 cards")
+// z95 is the normal quantile for a two-sided 95% confidence interval.
+const z95 = 1.96
+
+// chunkTrials is how many trials share one random stream; the target
+// precision is checked after every chunk.
+const chunkTrials = 1000
+
+// minPrecisionTrials is how many trials run before a target precision may
+// stop the simulation, so early runs of identical outcomes cannot end it.
+const minPrecisionTrials = 1000
+
+// maxRangeDraws is how many times a trial redraws range combos that share a
+// card before giving up.
+const maxRangeDraws = 1000
+
+// ctxCheckTrials is how often, in trials, a worker checks for cancellation.
+const ctxCheckTrials = 64
+
+// MonteCarloOptions controls a Monte Carlo simulation.
+type MonteCarloOptions struct {
+	// Simulations is the number of trials, or the most that will run when
+	// Precision is set. With a TimeBudget, 0 runs trials until it runs out.
+	Simulations int
+	// Precision, if positive, stops the simulation once the 95% confidence
+	// interval is no wider than ±Precision, e.g. 0.005 for ±0.5%.
+	Precision float64
+	// Workers is how many goroutines share the trials; 0 means one per CPU.
+	// It is capped by the size of Pool and by the number of chunkTrials-sized
+	// chunks the trials split into, so small simulations run on fewer.
+	Workers int
+	// Pool, if set, limits how many simulation and enumeration goroutines
+	// run at once across every calculation that shares it.
+	Pool *WorkerPool
+	// Seed, if set, makes the simulation reproducible; otherwise one is
+	// picked from the clock and reported in the result.
+	Seed *int64
+	// TimeBudget, if positive, stops the simulation when it runs out and
+	// returns the estimate reached so far. MethodAuto also bounds exact
+	// enumeration by it, sampling instead when enumeration runs long.
+	TimeBudget time.Duration
+	// Dead lists cards known to be out of play, such as folded or exposed
+	// cards; they are never dealt, whether simulating or enumerating.
+	Dead []Card
+}
+
+// returns how many workers the simulation will use.
+func (o MonteCarloOptions) workers() int {
+	n := o.Workers
+	if n == 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if o.Pool != nil {
+		n = min(n, o.Pool.Size())
+	}
+	chunks := (o.Simulations + chunkTrials - 1) / chunkTrials
+	return max(1, min(n, chunks))
+}
+
+// EquityResult summarises how the hero fares over the deals evaluated.
+type EquityResult struct {
+	// Win, Tie and Loss are the fractions of deals where the hero takes the
+	// whole pot, part of it, or none of it.
+	Win, Tie, Loss float64
+	// Equity is the hero's expected share of the pot.
+	Equity float64
+	// StdErr is the standard error of Equity, and CILow and CIHigh bound its
+	// 95% confidence interval. All three are exact (zero width) for
+	// MethodExact.
+	StdErr, CILow, CIHigh float64
+	// Simulations is the number of deals evaluated.
+	Simulations int
+	// Method is how the deals were chosen.
+	Method Method
+	// Seed is the seed a Monte Carlo simulation ran with.
+	Seed int64
+	// TimedOut reports that the time budget ran out before every trial ran.
+	TimedOut bool
+	// Categories is how often the player's final high hand falls in each
+	// category, and OpponentCategories the same for the best high hand
+	// among the other players. Categories that never occur are left out.
+	Categories, OpponentCategories map[Category]float64
+}
+
+// tally accumulates pot shares into an EquityResult.
+type tally struct {
+	wins, ties, deals         int
+	share, shareSq            float64
+	categories, oppCategories [numCategories]int
+}
+
+// records one deal: the player's pot share, their high hand category and
+// that of the best opposing high hand.
+func (t *tally) add(share float64, category, oppCategory Category) {
+	t.categories[category]++
+	t.oppCategories[oppCategory]++
+	switch {
+	case share >= 1:
+		t.wins++
+	case share > 0:
+		t.ties++
+	}
+	t.share += share
+	t.shareSq += share * share
+	t.deals++
+}
+
+// adds another tally's trials to t.
+func (t *tally) merge(o tally) {
+	t.wins += o.wins
+	t.ties += o.ties
+	t.deals += o.deals
+	t.share += o.share
+	t.shareSq += o.shareSq
+	for c := range t.categories {
+		t.categories[c] += o.categories[c]
+		t.oppCategories[c] += o.oppCategories[c]
+	}
+}
+
+// returns the standard error of the mean pot share.
+func (t tally) stdErr() float64 {
+	if t.deals < 2 {
+		return 0
+	}
+	n := float64(t.deals)
+	mean := t.share / n
+	variance := (t.shareSq - n*mean*mean) / (n - 1)
+	if variance <= 0 {
+		return 0
 	}
-	if !(len(community) == 0 || len(community) == 3 || len(community) == 4 || len(community) == 5) {
-		return 0, errors.New("community must have 0, 3, 4, or 5 cards")
+	return math.Sqrt(variance / n)
+}
+
+func (t tally) result(m Method, seed int64) EquityResult {
+	res := EquityResult{Simulations: t.deals, Method: m, Seed: seed}
+	if t.deals == 0 {
+		// nothing is known yet, so the interval covers every equity
+		res.CIHigh = 1
+		return res
 	}
-	if players < 2 {
-		return 0, errors.New("players must be >= 2")
+	n := float64(t.deals)
+	res.Win = float64(t.wins) / n
+	res.Tie = float64(t.ties) / n
+	res.Loss = float64(t.deals-t.wins-t.ties) / n
+	res.Equity = t.share / n
+	if m != MethodExact {
+		res.StdErr = t.stdErr()
 	}
-	if sims <= 0 {
-		return 0, errors.New("simulations must be > 0")
+	res.CILow = math.Max(0, res.Equity-z95*res.StdErr)
+	res.CIHigh = math.Min(1, res.Equity+z95*res.StdErr)
+	res.Categories = categoryFreqs(t.categories, n)
+	res.OpponentCategories = categoryFreqs(t.oppCategories, n)
+	return res
+}
+
+// converts category counts over n deals to frequencies.
+func categoryFreqs(counts [numCategories]int, n float64) map[Category]float64 {
+	freqs := map[Category]float64{}
+	for c, count := range counts {
+		if count > 0 {
+			freqs[Category(c)] = float64(count) / n
+		}
 	}
+	return freqs
+}
 
-	// build a deck with all known cards removed.
-	known := append([]Card{}, hole...)
-	known = append(known, community...)
-	deck, err := RemoveCards(NewDeck(), known)
+// MonteCarlo estimates win probability for the given hole cards and community.
+// Ties split the pot evenly and count towards Equity as fractional wins.
+func MonteCarlo(hole []Card, community []Card, players int, sims int) (EquityResult, error) {
+	return MonteCarloVariant(Holdem, hole, community, players, sims)
+}
+
+// MonteCarloVariant is MonteCarlo under the given variant's rules. Opponents
+// are dealt as many hole cards as the hero holds at showdown. In hi-lo games
+// the pot is split between the best high and the best qualifying low, so
+// scoops, halves and quarters all count as fractional wins.
+func MonteCarloVariant(v Variant, hole []Card, community []Card, players int, sims int) (EquityResult, error) {
+	return MonteCarloWithOptions(v, hole, community, players, MonteCarloOptions{Simulations: sims})
+}
+
+// MonteCarloWithOptions is MonteCarloVariant with a seed, a target precision
+// and a worker pool. Trials run in chunks of chunkTrials, each on its own
+// random stream derived from the seed, and chunk tallies are merged in chunk
+// order, so a seeded run gives the same result with any number of workers.
+func MonteCarloWithOptions(v Variant, hole []Card, community []Card, players int, opts MonteCarloOptions) (EquityResult, error) {
+	return MonteCarloContext(context.Background(), v, hole, community, players, opts)
+}
+
+// MonteCarloContext is MonteCarloWithOptions that stops with ctx's error when
+// ctx is cancelled. Running out of opts.TimeBudget is not an error: the
+// result covers the trials finished in time and has TimedOut set.
+func MonteCarloContext(ctx context.Context, v Variant, hole []Card, community []Card, players int, opts MonteCarloOptions) (EquityResult, error) {
+	d, err := newDeal(v, hole, community, players, opts.Dead)
+	if err != nil {
+		return EquityResult{}, err
+	}
+	res, err := d.simulate(ctx, opts)
 	if err != nil {
-		return 0, err
-	}
-
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	wins := 0.0
-
-	for i := 0; i < sims; i++ {
-		// shuffle a fresh copy of the remaining deck for this trial
-		simDeck := append([]Card{}, deck...)
-		rng.Shuffle(len(simDeck), func(i, j int) { simDeck[i], simDeck[j] = simDeck[j], simDeck[i] })
-		idx := 0
-
-		// complete the community board
-		neededCommunity := 5 - len(community)
-		board := append([]Card{}, community...)
-		board = append(board, simDeck[idx:idx+neededCommunity]...)
-		idx += neededCommunity
-
-		// evaluate hero hand against the completed board
-		hero7 := append([]Card{}, hole...)
-		hero7 = append(hero7, board...)
-		heroRank, err := Evaluate7(hero7)
-		if err != nil {
-			return 0, err
+		return EquityResult{}, err
+	}
+	return res[0], nil
+}
+
+// estimates every seat's equity by simulation.
+func (d deal) simulate(ctx context.Context, opts MonteCarloOptions) ([]EquityResult, error) {
+	if opts.Simulations == 0 && opts.TimeBudget > 0 {
+		// the budget alone bounds the simulation
+		opts.Simulations = math.MaxInt32
+	}
+	if opts.Simulations <= 0 {
+		return nil, errors.New("simulations must be > 0")
+	}
+	if opts.Precision < 0 || opts.Precision >= 1 {
+		return nil, errors.New("precision must be between 0 and 1")
+	}
+	if opts.Workers < 0 {
+		return nil, errors.New("workers must be >= 0")
+	}
+	if opts.TimeBudget < 0 {
+		return nil, errors.New("time budget must be >= 0")
+	}
+	seed := time.Now().UnixNano()
+	if opts.Seed != nil {
+		seed = *opts.Seed
+	}
+
+	// workers watch runCtx, which also ends when the time budget runs out
+	runCtx := ctx
+	if opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, opts.TimeBudget)
+		defer cancel()
+	}
+
+	workers := make([]*simWorker, opts.workers())
+	for i := range workers {
+		workers[i] = newSimWorker(d)
+	}
+	chunks := (opts.Simulations + chunkTrials - 1) / chunkTrials
+
+	tallies := make([]tally, len(d.seats))
+	results := func(timedOut bool) []EquityResult {
+		res := make([]EquityResult, len(tallies))
+		for i, t := range tallies {
+			res[i] = t.result(MethodMonteCarlo, seed)
+			res[i].TimedOut = timedOut
+		}
+		return res
+	}
+	errs := make([]error, len(workers))
+	for first := 0; first < chunks; first += len(workers) {
+		// each worker runs the next chunk in turn
+		var wg sync.WaitGroup
+		for i, w := range workers {
+			c := first + i
+			if c >= chunks {
+				break
+			}
+			n := min(chunkTrials, opts.Simulations-c*chunkTrials)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.reset()
+				if opts.Pool.acquire(runCtx) != nil {
+					return
+				}
+				defer opts.Pool.release()
+				errs[i] = w.run(runCtx, chunkSeed(seed, c), n)
+			}()
+		}
+		wg.Wait()
+
+		for i, w := range workers {
+			if first+i >= chunks {
+				break
+			}
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			for j := range tallies {
+				tallies[j].merge(w.tallies[j])
+			}
+
+			// stop early once every interval is tight enough
+			if opts.Precision > 0 && tallies[0].deals >= minPrecisionTrials && precise(tallies, opts.Precision) {
+				return results(false), nil
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if runCtx.Err() != nil && tallies[0].deals < opts.Simulations {
+			return results(true), nil
+		}
+	}
+
+	return results(false), nil
+}
+
+// reports whether every tally's 95% confidence interval is within ±precision.
+func precise(tallies []tally, precision float64) bool {
+	for _, t := range tallies {
+		if z95*t.stdErr() > precision {
+			return false
 		}
+	}
+	return true
+}
+
+// returns the seed of a chunk's random stream, mixing the chunk index into
+// the simulation seed with SplitMix64 so neighbouring chunks are unrelated.
+func chunkSeed(seed int64, chunk int) int64 {
+	z := uint64(seed) + uint64(chunk+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// simWorker runs chunks of trials with its own random stream and buffers.
+type simWorker struct {
+	d       deal
+	src     rand.Source
+	rng     *rand.Rand
+	tallies []tally
 
-		// track best opponent hand and how many players share it (for ties)
-		bestOpp := heroRank
-		winners := 1
-
-		// deal random hole cards to the remaining players and evaluate them
-		for p := 0; p < players-1; p++ {
-			oppHole := []Card{simDeck[idx], simDeck[idx+1]}
-			idx += 2
-			opp7 := append([]Card{}, oppHole...)
-			opp7 = append(opp7, board...)
-			oppRank, err := Evaluate7(opp7)
+	// reuse buffers across trials so the hot loop does not allocate
+	simDeck, board []Card
+	holes          [][]Card
+	showdown       *showdown
+}
+
+func newSimWorker(d deal) *simWorker {
+	board, holes := d.buffers()
+	src := rand.NewSource(0)
+	return &simWorker{
+		d:        d,
+		src:      src,
+		rng:      rand.New(src),
+		tallies:  make([]tally, len(d.seats)),
+		simDeck:  make([]Card, len(d.deck)),
+		board:    board,
+		holes:    holes,
+		showdown: newShowdown(len(d.seats)),
+	}
+}
+
+// clears the worker's tallies before a chunk.
+func (w *simWorker) reset() {
+	for i := range w.tallies {
+		w.tallies[i] = tally{}
+	}
+}
+
+// runs n trials from the given seed and adds them to the worker's tallies,
+// stopping early if ctx is done.
+func (w *simWorker) run(ctx context.Context, seed int64, n int) error {
+	w.src.Seed(seed)
+	for i := 0; i < n; i++ {
+		if i%ctxCheckTrials == 0 && ctx.Err() != nil {
+			return nil
+		}
+
+		// shuffle a fresh copy of the remaining deck for this trial, then
+		// complete the board and every hand from it
+		deck := w.simDeck[:copy(w.simDeck, w.d.deck)]
+		if w.d.ranges != nil {
+			used, err := w.drawRanges()
 			if err != nil {
-				return 0, err
+				return err
 			}
-			cmp := Compare(oppRank, bestOpp)
-			if cmp > 0 {
-				bestOpp = oppRank
-				winners = 1
-			} else if cmp == 0 {
-				winners++
+			deck = w.simDeck[:0]
+			for _, c := range w.d.deck {
+				if !used.Contains(c) {
+					deck = append(deck, c)
+				}
 			}
 		}
+		w.rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+		w.d.fill(w.board, w.holes, deck)
 
-		// if hero ties for best hand, count fractional win
-		if Compare(heroRank, bestOpp) == 0 {
-			wins += 1.0 / float64(winners)
+		if err := w.d.settle(w.showdown, w.holes, w.board); err != nil {
+			return err
+		}
+		for j := range w.tallies {
+			w.tallies[j].add(w.showdown.shares[j], w.showdown.category[j], w.showdown.oppCategory[j])
 		}
 	}
+	return nil
+}
 
-	// return average win probability across all simulations
-	return wins / float64(sims), nil
+// deals every range seat a combo from its range, redrawing all of them
+// until no two share a card, and returns the cards dealt.
+func (w *simWorker) drawRanges() (CardSet, error) {
+	for attempt := 0; attempt < maxRangeDraws; attempt++ {
+		var used CardSet
+		ok := true
+		for seat, hole := range w.holes {
+			if !w.d.isRange(seat) {
+				continue
+			}
+			combo := w.d.sampleRange(seat, w.rng)
+			set := NewCardSet(combo[0], combo[1])
+			if set.Intersect(used) != 0 {
+				ok = false
+				break
+			}
+			used = used.Union(set)
+			copy(hole, combo[:])
+		}
+		if ok {
+			return used, nil
+		}
+	}
+	return 0, errors.New("ranges conflict with each other too often to deal")
 }
@@ -0,0 +1,57 @@
+package poker
+
+import "errors"
+
+const (
+	omahaMinHole = 4
+	omahaMaxHole = 6
+)
+
+var errOmahaHand = errors.New("omaha needs 4 to 6 hole cards and 3 to 5 board cards")
+
+// EvaluateOmaha computes the best hand using exactly two hole cards and
+// exactly three board cards.
+func EvaluateOmaha(hole, board []Card) (HandRank, error) {
+	score, best, err := scoreOmaha(standardTables, hole, board)
+	if err != nil {
+		return HandRank{}, err
+	}
+	return newHandRank(score, append([]Card{}, best[:]...)), nil
+}
+
+// ScoreOmaha scores an Omaha hand without allocating, for use in hot loops.
+func ScoreOmaha(hole, board []Card) (Score, error) {
+	score, _, err := scoreOmaha(standardTables, hole, board)
+	return score, err
+}
+
+// tries every pair of hole cards with every triple of board cards.
+func scoreOmaha(t *rankTables, hole, board []Card) (Score, [5]Card, error) {
+	var best [5]Card
+	if len(hole) < omahaMinHole || len(hole) > omahaMaxHole || len(board) < 3 || len(board) > 5 {
+		return 0, best, errOmahaHand
+	}
+	var bestScore Score
+	var five [5]Card
+	for a := 0; a < len(hole); a++ {
+		for b := a + 1; b < len(hole); b++ {
+			five[0], five[1] = hole[a], hole[b]
+			for c := 0; c < len(board); c++ {
+				for d := c + 1; d < len(board); d++ {
+					for e := d + 1; e < len(board); e++ {
+						five[2], five[3], five[4] = board[c], board[d], board[e]
+						s, err := t.score(five[:])
+						if err != nil {
+							return 0, best, err
+						}
+						if s > bestScore {
+							bestScore = s
+							best = five
+						}
+					}
+				}
+			}
+		}
+	}
+	return bestScore, best, nil
+}
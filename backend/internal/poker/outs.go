@@ -0,0 +1,129 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Out is an unseen card that takes the hero from behind to ahead.
+type Out struct {
+	Card Card
+	// Category is the hero's high hand category once the card is dealt.
+	Category Category
+	// Weight is the share of the opponent's range the card turns around:
+	// 1 against a known hand, possibly less against a range.
+	Weight float64
+}
+
+// OutsResult lists the hero's outs on a flop or turn.
+type OutsResult struct {
+	Outs []Out
+	// Count is the number of outs, counting each by its weight.
+	Count float64
+	// ByCategory sums the outs by the hand the hero makes with them.
+	ByCategory map[Category]float64
+	// Unseen is the number of cards that can still come.
+	Unseen int
+	// TurnProbability is the chance the turn is an out (0 on the turn), and
+	// RiverProbability the chance of hitting an out by the river. Neither
+	// counts runner-runner draws.
+	TurnProbability, RiverProbability float64
+}
+
+// Outs finds every unseen card that takes the hero from behind to ahead of
+// the opponent's range on a 3 or 4 card board, without the dead cards. Pass a
+// single-combo range for a known opponent hand. Hands are scored on the
+// board as it stands, so only games with two hole cards are supported.
+func Outs(v Variant, hole, board []Card, opp Range, dead []Card) (OutsResult, error) {
+	r, err := v.rules()
+	if err != nil {
+		return OutsResult{}, err
+	}
+	if r.boardCards == 0 || r.dealtHole != 0 || r.checkHole(2) != nil {
+		return OutsResult{}, fmt.Errorf("outs are not supported for %s", v)
+	}
+	if err := r.checkHole(len(hole)); err != nil {
+		return OutsResult{}, err
+	}
+	if len(board) != 3 && len(board) != 4 {
+		return OutsResult{}, errors.New("community must have 3 or 4 cards")
+	}
+	if err := r.checkCards(hole, board, dead); err != nil {
+		return OutsResult{}, err
+	}
+	known := NewCardSet(hole...).Union(NewCardSet(board...))
+	if known.Count() != len(hole)+len(board) {
+		return OutsResult{}, errors.New("hole and community cards must not repeat")
+	}
+	if known.Intersect(NewCardSet(dead...)) != 0 {
+		return OutsResult{}, errors.New("dead cards must not be in the hand or on the board")
+	}
+	unavailable := known.Union(NewCardSet(dead...))
+	opp = opp.Without(unavailable.Union(r.deck ^ FullDeck))
+	if len(opp) == 0 {
+		return OutsResult{}, errors.New("opponent range has no combos left after card removal")
+	}
+
+	// score everyone on the board as it stands
+	hero, err := r.score(hole, board)
+	if err != nil {
+		return OutsResult{}, err
+	}
+	before := make([]Score, len(opp))
+	for i, c := range opp {
+		if before[i], err = r.score(c.Cards[:], board); err != nil {
+			return OutsResult{}, err
+		}
+	}
+
+	res := OutsResult{ByCategory: map[Category]float64{}}
+	next := append(append([]Card{}, board...), 0)
+	for _, c := range r.deck.Difference(unavailable).Cards() {
+		next[len(board)] = c
+		heroAfter, err := r.score(hole, next)
+		if err != nil {
+			return OutsResult{}, err
+		}
+
+		// weigh the combos that could still be out there once c is dealt
+		live, turned := 0.0, 0.0
+		for i, combo := range opp {
+			if combo.Set().Contains(c) {
+				continue
+			}
+			live += combo.Weight
+			if hero >= before[i] {
+				continue
+			}
+			after, err := r.score(combo.Cards[:], next)
+			if err != nil {
+				return OutsResult{}, err
+			}
+			if heroAfter > after {
+				turned += combo.Weight
+			}
+		}
+		if live == 0 {
+			// the opponent holds c
+			continue
+		}
+		res.Unseen++
+		if turned == 0 {
+			continue
+		}
+		out := Out{Card: c, Category: heroAfter.Category(), Weight: turned / live}
+		res.Outs = append(res.Outs, out)
+		res.Count += out.Weight
+		res.ByCategory[out.Category] += out.Weight
+	}
+
+	// chance of hitting with the next card, and with either of the next two
+	n, k := float64(res.Unseen), res.Count
+	if len(board) == 3 {
+		res.TurnProbability = k / n
+		res.RiverProbability = 1 - (n-k)*(n-1-k)/(n*(n-1))
+	} else {
+		res.RiverProbability = k / n
+	}
+	return res, nil
+}
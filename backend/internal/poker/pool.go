@@ -0,0 +1,40 @@
+package poker
+
+import "context"
+
+// WorkerPool caps how many simulation goroutines run at once. Workers take a
+// slot for each round of trials rather than for a whole simulation, so a
+// large request interleaves with others instead of holding every slot. Exact
+// enumeration runs on a single goroutine that holds one slot throughout.
+type WorkerPool struct {
+	slots chan struct{}
+}
+
+// NewWorkerPool creates a pool that runs at most n goroutines at once.
+func NewWorkerPool(n int) *WorkerPool {
+	return &WorkerPool{slots: make(chan struct{}, max(1, n))}
+}
+
+// Size returns the most goroutines the pool runs at once.
+func (p *WorkerPool) Size() int {
+	return cap(p.slots)
+}
+
+// waits for a free slot, giving up with ctx's error when ctx is done.
+func (p *WorkerPool) acquire(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) release() {
+	if p != nil {
+		<-p.slots
+	}
+}
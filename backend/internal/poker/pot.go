@@ -0,0 +1,154 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PotPlayer is one seat at a showdown, in seat order.
+type PotPlayer struct {
+	// Committed is every chip the player put in the pot this hand.
+	Committed int64
+	// Folded players fund the pots but cannot win them.
+	Folded bool
+	// High is the player's best hand; Low and HasLow their qualifying low,
+	// if any, in hi-lo games.
+	High   HandRank
+	Low    HandRank
+	HasLow bool
+}
+
+// Pot is the main pot or one side pot.
+type Pot struct {
+	Amount int64
+	// Eligible lists the seats that can win the pot.
+	Eligible []int
+	// Winners lists the seats that win the high half (or all) of the pot,
+	// and LowWinners those that win the low half in hi-lo games.
+	Winners    []int
+	LowWinners []int
+}
+
+// PotResult is how the chips are shared out.
+type PotResult struct {
+	// Pots lists the main pot first, then each side pot.
+	Pots []Pot
+	// Payouts is what each seat collects.
+	Payouts []int64
+}
+
+// DistributePots builds the main and side pots from each seat's committed
+// chips and awards each to its best eligible hands under Compare. A pot is
+// split high and low when an eligible seat holds a qualifying low, the odd
+// chip going high. Chips that do not split evenly go one at a time to the
+// winners in seat order starting left of the button.
+func DistributePots(players []PotPlayer, button int) (PotResult, error) {
+	if len(players) < 2 {
+		return PotResult{}, errors.New("pot needs at least 2 players")
+	}
+	if button < 0 || button >= len(players) {
+		return PotResult{}, fmt.Errorf("button must be a seat from 0 to %d", len(players)-1)
+	}
+	var levels []int64
+	var total int64
+	for i, p := range players {
+		if p.Committed < 0 {
+			return PotResult{}, fmt.Errorf("player %d committed negative chips", i+1)
+		}
+		if p.Committed > math.MaxInt64-total {
+			return PotResult{}, errors.New("total chips committed are too large")
+		}
+		total += p.Committed
+		if !p.Folded {
+			levels = append(levels, p.Committed)
+		}
+	}
+	if len(levels) == 0 {
+		return PotResult{}, errors.New("every player folded")
+	}
+	sort.Slice(levels, func(a, b int) bool { return levels[a] < levels[b] })
+
+	res := PotResult{Payouts: make([]int64, len(players))}
+	var prev int64
+	for i, level := range levels {
+		if i > 0 && level == prev {
+			continue
+		}
+		last := level == levels[len(levels)-1]
+		var pot Pot
+		for seat, p := range players {
+			// the last pot also takes folded chips above every live stack
+			take := min(p.Committed, level)
+			if last {
+				take = p.Committed
+			}
+			pot.Amount += max(take-prev, 0)
+			if !p.Folded && p.Committed >= level {
+				pot.Eligible = append(pot.Eligible, seat)
+			}
+		}
+		prev = level
+		if pot.Amount == 0 {
+			continue
+		}
+		pot.Winners = bestSeats(players, pot.Eligible, false)
+		pot.LowWinners = bestSeats(players, pot.Eligible, true)
+		high := pot.Amount
+		if pot.LowWinners != nil {
+			high -= pot.Amount / 2
+			award(res.Payouts, pot.LowWinners, pot.Amount/2, button)
+		}
+		award(res.Payouts, pot.Winners, high, button)
+		res.Pots = append(res.Pots, pot)
+	}
+	return res, nil
+}
+
+// returns the eligible seats holding the best high hand, or the best
+// qualifying low (nil if nobody has one).
+func bestSeats(players []PotPlayer, eligible []int, low bool) []int {
+	var best []int
+	for _, seat := range eligible {
+		hand := players[seat].High
+		if low {
+			if !players[seat].HasLow {
+				continue
+			}
+			hand = players[seat].Low
+		}
+		if best == nil {
+			best = []int{seat}
+			continue
+		}
+		top := players[best[0]].High
+		if low {
+			top = players[best[0]].Low
+		}
+		switch Compare(hand, top) {
+		case 1:
+			best = []int{seat}
+		case 0:
+			best = append(best, seat)
+		}
+	}
+	return best
+}
+
+// splits amount evenly among the winners, handing the odd chips out in seat
+// order starting left of the button.
+func award(payouts []int64, winners []int, amount int64, button int) {
+	n := int64(len(winners))
+	order := append([]int{}, winners...)
+	seats := len(payouts)
+	sort.Slice(order, func(a, b int) bool {
+		return (order[a]-button-1+seats)%seats < (order[b]-button-1+seats)%seats
+	})
+	for i, seat := range order {
+		payouts[seat] += amount / n
+		if int64(i) < amount%n {
+			payouts[seat]++
+		}
+	}
+}
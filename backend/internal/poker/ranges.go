@@ -0,0 +1,235 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Combo is one two-card holding in a range, weighted by how often the player
+// holds it (0 < Weight <= 1).
+type Combo struct {
+	Cards  [2]Card
+	Weight float64
+}
+
+// Range is a list of weighted combos, each listed once.
+type Range []Combo
+
+// Set returns the two cards of the combo as a set.
+func (c Combo) Set() CardSet {
+	return NewCardSet(c.Cards[0], c.Cards[1])
+}
+
+// ParseRange parses standard range notation: comma separated tokens such as
+// "QQ+", "22-55", "AKs", "AKo", "AK", "KTs+", "AJo-ATo", "AhKh" (or "HASK")
+// and "random" (every combo). A token may end in ":weight" to hold those
+// combos only part of the time, e.g. "AKo:0.5"; a combo listed twice keeps
+// its last weight.
+func ParseRange(s string) (Range, error) {
+	var r Range
+	index := map[CardSet]int{}
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		combos, weight, err := parseRangeToken(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, cards := range combos {
+			set := NewCardSet(cards[0], cards[1])
+			if i, ok := index[set]; ok {
+				r[i].Weight = weight
+				continue
+			}
+			index[set] = len(r)
+			r = append(r, Combo{Cards: cards, Weight: weight})
+		}
+	}
+	if len(r) == 0 {
+		return nil, errors.New("range is empty")
+	}
+	return r, nil
+}
+
+// Without returns the combos that share no card with dead, for removing
+// known and dead cards from a range.
+func (r Range) Without(dead CardSet) Range {
+	out := make(Range, 0, len(r))
+	for _, c := range r {
+		if c.Set().Intersect(dead) == 0 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// TotalWeight returns the sum of the combo weights.
+func (r Range) TotalWeight() float64 {
+	total := 0.0
+	for _, c := range r {
+		total += c.Weight
+	}
+	return total
+}
+
+// parses one range token into its combos and weight.
+func parseRangeToken(token string) ([][2]Card, float64, error) {
+	weight := 1.0
+	if i := strings.IndexByte(token, ':'); i >= 0 {
+		w, err := strconv.ParseFloat(strings.TrimSpace(token[i+1:]), 64)
+		if err != nil || w <= 0 || w > 1 {
+			return nil, 0, fmt.Errorf("invalid weight in '%s'", token)
+		}
+		token, weight = strings.TrimSpace(token[:i]), w
+	}
+
+	switch strings.ToLower(token) {
+	case "random", "any":
+		return allCombos(), weight, nil
+	}
+	if cards, ok := parseComboCards(token); ok {
+		return [][2]Card{cards}, weight, nil
+	}
+
+	var combos [][2]Card
+	switch {
+	case strings.HasSuffix(token, "+"):
+		c, err := parseHandClass(token[:len(token)-1])
+		if err != nil {
+			return nil, 0, err
+		}
+		// pairs go up to aces, other hands raise the kicker below the top card
+		top := numRanks - 1
+		if !c.pair() {
+			top = c.hi - 1
+		}
+		for k := c.lo; k <= top; k++ {
+			combos = append(combos, c.with(k).combos()...)
+		}
+	case strings.Contains(token, "-"):
+		from, to, _ := strings.Cut(token, "-")
+		a, err := parseHandClass(from)
+		if err != nil {
+			return nil, 0, err
+		}
+		b, err := parseHandClass(to)
+		if err != nil {
+			return nil, 0, err
+		}
+		if a.pair() != b.pair() || a.suited != b.suited || (!a.pair() && a.hi != b.hi) {
+			return nil, 0, fmt.Errorf("invalid span '%s'", token)
+		}
+		lo, hi := min(a.lo, b.lo), max(a.lo, b.lo)
+		for k := lo; k <= hi; k++ {
+			combos = append(combos, a.with(k).combos()...)
+		}
+	default:
+		c, err := parseHandClass(token)
+		if err != nil {
+			return nil, 0, err
+		}
+		combos = c.combos()
+	}
+	return combos, weight, nil
+}
+
+// handClass is a starting hand without suits, like "AKs" or "77".
+type handClass struct {
+	hi, lo int
+	// suited is 's', 'o', or 0 for both.
+	suited byte
+}
+
+// parses a class such as "AK", "AKs", "ako" or "77".
+func parseHandClass(s string) (handClass, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 2 && len(s) != 3 {
+		return handClass{}, fmt.Errorf("invalid hand '%s'", s)
+	}
+	up := strings.ToUpper(s)
+	a, b := rankIndex[up[0]], rankIndex[up[1]]
+	if a < 0 || b < 0 {
+		return handClass{}, fmt.Errorf("invalid hand '%s'", s)
+	}
+	c := handClass{hi: int(max(a, b)), lo: int(min(a, b))}
+	if len(s) == 3 {
+		c.suited = strings.ToLower(s)[2]
+		if (c.suited != 's' && c.suited != 'o') || c.pair() {
+			return handClass{}, fmt.Errorf("invalid hand '%s'", s)
+		}
+	}
+	return c, nil
+}
+
+func (c handClass) pair() bool {
+	return c.hi == c.lo
+}
+
+// returns the class with its lower card, or both cards of a pair, set to k.
+func (c handClass) with(k int) handClass {
+	if c.pair() {
+		c.hi = k
+	}
+	c.lo = k
+	return c
+}
+
+// returns every suit combination of the class.
+func (c handClass) combos() [][2]Card {
+	var combos [][2]Card
+	for s1 := 0; s1 < numSuits; s1++ {
+		for s2 := 0; s2 < numSuits; s2++ {
+			if c.pair() && s2 <= s1 {
+				continue
+			}
+			if (c.suited == 's' && s1 != s2) || (c.suited == 'o' && s1 == s2) {
+				continue
+			}
+			combos = append(combos, [2]Card{
+				Card(s1*numRanks + c.hi),
+				Card(s2*numRanks + c.lo),
+			})
+		}
+	}
+	return combos
+}
+
+// returns all 1326 two-card combos.
+func allCombos() [][2]Card {
+	combos := make([][2]Card, 0, numCards*(numCards-1)/2)
+	for a := Card(0); a < numCards; a++ {
+		for b := a + 1; b < numCards; b++ {
+			combos = append(combos, [2]Card{a, b})
+		}
+	}
+	return combos
+}
+
+// parses an explicit combo written rank first ("AhKh") or in card codes
+// ("HASK").
+func parseComboCards(s string) ([2]Card, bool) {
+	if len(s) != 4 {
+		return [2]Card{}, false
+	}
+	up := strings.ToUpper(s)
+	var cards [2]Card
+	for i := 0; i < 2; i++ {
+		a, b := up[2*i], up[2*i+1]
+		if rankIndex[a] >= 0 {
+			a, b = b, a
+		}
+		c, err := NewCard(a, b)
+		if err != nil {
+			return [2]Card{}, false
+		}
+		cards[i] = c
+	}
+	if cards[0] == cards[1] {
+		return [2]Card{}, false
+	}
+	return cards, true
+}
@@ -0,0 +1,98 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RelativeStrength is how a hand ranks against every holding an opponent
+// could have on the same board.
+type RelativeStrength struct {
+	// Ahead, Tied and Behind count the opponent holdings the hand beats,
+	// ties and loses to.
+	Ahead, Tied, Behind int
+	// Percentile is the share of holdings beaten, counting ties as half.
+	Percentile float64
+	// Nuts reports that no holding beats the hand.
+	Nuts bool
+	// NutHands lists every holding that makes the best possible hand on the
+	// board, the hero's cards included, and NutScore is that hand's score.
+	NutHands [][2]Card
+	NutScore Score
+}
+
+// EvaluateRelative ranks two hole cards against every two-card holding left
+// in the deck on a board of 3 to 5 cards, as it stands. Dead cards are out of
+// the deck, so no holding contains them, and must not be in the hand or on
+// the board. Only games with two hole cards are supported.
+func EvaluateRelative(v Variant, hole, board, dead []Card) (RelativeStrength, error) {
+	r, err := v.rules()
+	if err != nil {
+		return RelativeStrength{}, err
+	}
+	if r.boardCards == 0 || r.dealtHole != 0 || r.checkHole(2) != nil {
+		return RelativeStrength{}, fmt.Errorf("relative strength is not supported for %s", v)
+	}
+	if err := r.checkHole(len(hole)); err != nil {
+		return RelativeStrength{}, err
+	}
+	if err := r.checkBoard(len(board)); err != nil {
+		return RelativeStrength{}, err
+	}
+	if err := r.checkCards(hole, board, dead); err != nil {
+		return RelativeStrength{}, err
+	}
+	boardSet := NewCardSet(board...)
+	heroSet := NewCardSet(hole...)
+	if boardSet.Union(heroSet).Count() != len(hole)+len(board) {
+		return RelativeStrength{}, errors.New("hole and community cards must not repeat")
+	}
+	deadSet := NewCardSet(dead...)
+	if deadSet.Count() != len(dead) {
+		return RelativeStrength{}, errors.New("dead cards must not repeat")
+	}
+	for _, c := range dead {
+		if boardSet.Contains(c) || heroSet.Contains(c) {
+			return RelativeStrength{}, fmt.Errorf("dead card '%s' is also in a hand or on the board", c)
+		}
+	}
+
+	hero, err := r.score(hole, board)
+	if err != nil {
+		return RelativeStrength{}, err
+	}
+	var res RelativeStrength
+	cards := r.deck.Difference(boardSet.Union(deadSet)).Cards()
+	var holding [2]Card
+	for i := 0; i < len(cards); i++ {
+		for j := i + 1; j < len(cards); j++ {
+			holding = [2]Card{cards[i], cards[j]}
+			score, err := r.score(holding[:], board)
+			if err != nil {
+				return RelativeStrength{}, err
+			}
+			switch {
+			case score > res.NutScore:
+				res.NutScore, res.NutHands = score, [][2]Card{holding}
+			case score == res.NutScore:
+				res.NutHands = append(res.NutHands, holding)
+			}
+			// holdings that share a card with the hero are only nut candidates
+			if heroSet.Contains(holding[0]) || heroSet.Contains(holding[1]) {
+				continue
+			}
+			switch {
+			case hero > score:
+				res.Ahead++
+			case hero == score:
+				res.Tied++
+			default:
+				res.Behind++
+			}
+		}
+	}
+	total := res.Ahead + res.Tied + res.Behind
+	res.Percentile = (float64(res.Ahead) + float64(res.Tied)/2) / float64(total)
+	res.Nuts = res.Behind == 0
+	return res, nil
+}
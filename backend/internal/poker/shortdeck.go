@@ -0,0 +1,48 @@
+package poker
+
+import "fmt"
+
+// ShortDeck is the 36-card six-plus deck: the full deck without deuces
+// through fives.
+var ShortDeck = func() CardSet {
+	var s CardSet
+	for _, c := range NewDeck() {
+		if c.RankValue() >= 6 {
+			s = s.Add(c)
+		}
+	}
+	return s
+}()
+
+// creates a 36-card short deck in a deterministic order.
+func NewShortDeck() []Card {
+	return ShortDeck.Cards()
+}
+
+// EvaluateShortDeck computes the best 5-card hand from 5 to 9 short-deck
+// cards, where a flush beats a full house and A-6-7-8-9 is a straight.
+func EvaluateShortDeck(cs []Card) (HandRank, error) {
+	score, err := ScoreShortDeck(cs)
+	if err != nil {
+		return HandRank{}, err
+	}
+	return newHandRank(score, bestFive(cs, score)), nil
+}
+
+// ScoreShortDeck scores 5 to 9 short-deck cards without allocating.
+func ScoreShortDeck(cs []Card) (Score, error) {
+	if err := checkDeck(cs, ShortDeck); err != nil {
+		return 0, err
+	}
+	return shortDeckTables.score(cs)
+}
+
+// rejects cards that cannot be dealt from the given deck.
+func checkDeck(cs []Card, deck CardSet) error {
+	for _, c := range cs {
+		if !deck.Contains(c) {
+			return fmt.Errorf("card '%s' is not in the deck", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxShowdownPlayers is the most hands RankShowdown compares at once.
+const MaxShowdownPlayers = 10
+
+// ShowdownResult ranks every hand at a showdown.
+type ShowdownResult struct {
+	// High is each player's best high hand.
+	High []HandRank
+	// Order groups the players by high hand from best to worst; the players
+	// in a group tie and split that share of the pot.
+	Order [][]int
+	// Low and HasLow are each player's best qualifying low in hi-lo games,
+	// and LowOrder groups the players holding one from best to worst.
+	Low      []HandRank
+	HasLow   []bool
+	LowOrder [][]int
+}
+
+// RankShowdown evaluates 2 to MaxShowdownPlayers hands sharing one board
+// (none for stud) and orders them. The board and every hand must be complete,
+// seven cards in stud, and no card may appear twice.
+func RankShowdown(v Variant, hands [][]Card, board []Card) (ShowdownResult, error) {
+	r, err := v.rules()
+	if err != nil {
+		return ShowdownResult{}, err
+	}
+	if len(hands) < 2 || len(hands) > MaxShowdownPlayers {
+		return ShowdownResult{}, fmt.Errorf("showdown needs 2 to %d players", MaxShowdownPlayers)
+	}
+	if err := r.checkShowdownBoard(len(board)); err != nil {
+		return ShowdownResult{}, err
+	}
+	if err := r.checkCards(board); err != nil {
+		return ShowdownResult{}, err
+	}
+	seen := NewCardSet(board...)
+	total := len(board)
+	for i, hole := range hands {
+		if err := r.checkShowdownHole(len(hole)); err != nil {
+			return ShowdownResult{}, fmt.Errorf("player %d: %w", i+1, err)
+		}
+		if err := r.checkCards(hole); err != nil {
+			return ShowdownResult{}, fmt.Errorf("player %d: %w", i+1, err)
+		}
+		seen = seen.Union(NewCardSet(hole...))
+		total += len(hole)
+	}
+	if seen.Count() != total {
+		return ShowdownResult{}, errors.New("cards must not repeat across players and board")
+	}
+
+	res := ShowdownResult{High: make([]HandRank, len(hands))}
+	high := make([]Score, len(hands))
+	for i, hole := range hands {
+		if res.High[i], err = r.evaluate(hole, board); err != nil {
+			return ShowdownResult{}, err
+		}
+		high[i] = res.High[i].Score
+	}
+	res.Order = groupScores(high)
+	if r.evaluateLow == nil {
+		return res, nil
+	}
+
+	res.Low = make([]HandRank, len(hands))
+	res.HasLow = make([]bool, len(hands))
+	low := make([]Score, len(hands))
+	for i, hole := range hands {
+		if res.Low[i], res.HasLow[i], err = r.evaluateLow(hole, board); err != nil {
+			return ShowdownResult{}, err
+		}
+		if res.HasLow[i] {
+			low[i] = res.Low[i].Score
+		}
+	}
+	res.LowOrder = groupScores(low)
+	// players without a qualifying low score 0 and sort last
+	if n := len(res.LowOrder); n > 0 && !res.HasLow[res.LowOrder[n-1][0]] {
+		res.LowOrder = res.LowOrder[:n-1]
+	}
+	return res, nil
+}
+
+// CheckShowdownBoard reports whether the board is complete for a showdown in
+// the variant: five cards, or none in stud.
+func CheckShowdownBoard(v Variant, board []Card) error {
+	r, err := v.rules()
+	if err != nil {
+		return err
+	}
+	return r.checkShowdownBoard(len(board))
+}
+
+// CheckShowdownHand reports whether a hand is complete for a showdown in the
+// variant, holding every card dealt in stud.
+func CheckShowdownHand(v Variant, hole []Card) error {
+	r, err := v.rules()
+	if err != nil {
+		return err
+	}
+	return r.checkShowdownHole(len(hole))
+}
+
+// groups player indexes by score from best to worst, keeping seat order
+// within a group.
+func groupScores(scores []Score) [][]int {
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+	var groups [][]int
+	for i, p := range idx {
+		if i > 0 && scores[p] == scores[idx[i-1]] {
+			groups[len(groups)-1] = append(groups[len(groups)-1], p)
+			continue
+		}
+		groups = append(groups, []int{p})
+	}
+	return groups
+}
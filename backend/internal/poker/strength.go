@@ -0,0 +1,141 @@
+package poker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NumStrengthClasses is the number of distinct 5-card hand values. Every hand
+// falls into exactly one class, numbered 1 (7-5-4-3-2 offsuit) to
+// NumStrengthClasses (royal flush).
+const NumStrengthClasses = 7462
+
+// rank names by value; the ace is 1 when it plays low.
+var rankNames = map[int][2]string{
+	1:  {"Ace", "Aces"},
+	2:  {"Two", "Twos"},
+	3:  {"Three", "Threes"},
+	4:  {"Four", "Fours"},
+	5:  {"Five", "Fives"},
+	6:  {"Six", "Sixes"},
+	7:  {"Seven", "Sevens"},
+	8:  {"Eight", "Eights"},
+	9:  {"Nine", "Nines"},
+	10: {"Ten", "Tens"},
+	11: {"Jack", "Jacks"},
+	12: {"Queen", "Queens"},
+	13: {"King", "Kings"},
+	14: {"Ace", "Aces"},
+}
+
+// collects every distinct 5-card score and numbers them from weakest to strongest.
+func (t *rankTables) buildStrengthClasses() {
+	seen := map[Score]struct{}{}
+	for _, s := range t.rankTable[rankTableOffset[5]:rankTableOffset[6]] {
+		if s != 0 {
+			seen[s] = struct{}{}
+		}
+	}
+	for _, s := range t.flushTable {
+		if s != 0 {
+			seen[s] = struct{}{}
+		}
+	}
+	t.classScores = make([]Score, 0, len(seen))
+	for s := range seen {
+		t.classScores = append(t.classScores, s)
+	}
+	sort.Slice(t.classScores, func(i, j int) bool { return t.classScores[i] < t.classScores[j] })
+	t.scoreClass = make(map[Score]int, len(t.classScores))
+	for i, s := range t.classScores {
+		t.scoreClass[s] = i + 1
+	}
+}
+
+// Strength returns the equivalence class of the score, from 1 for the weakest
+// hand up to NumStrengthClasses (or the short-deck equivalent) for the strongest.
+// Five of a kind, only possible with wild cards, ranks above every natural
+// hand: NumStrengthClasses+1 for fives up to NumStrengthClasses+13 for aces.
+func (s Score) Strength() int {
+	t := s.tables()
+	if s.Category() == FiveOfAKind {
+		return len(t.classScores) + s.Tiebreak()[0] - 1
+	}
+	return t.scoreClass[s]
+}
+
+// StrengthScore returns the score of the given strength class.
+func StrengthScore(class int) (Score, error) {
+	classes := standardTables.classScores
+	if class < 1 || class > len(classes) {
+		return 0, fmt.Errorf("strength must be between 1 and %d", len(classes))
+	}
+	return classes[class-1], nil
+}
+
+// DescribeStrength turns a strength class into a description like
+// "Ace-high flush, K-9-7-3 kicker".
+func DescribeStrength(class int) (string, error) {
+	s, err := StrengthScore(class)
+	if err != nil {
+		return "", err
+	}
+	return s.Describe(), nil
+}
+
+// Describe returns a human-readable description of the scored hand.
+func (s Score) Describe() string {
+	tb := s.Tiebreak()
+	switch s.Category() {
+	case FiveOfAKind:
+		return fmt.Sprintf("Five %s", rankNames[tb[0]][1])
+	case StraightFlush:
+		if tb[0] == 14 {
+			return "Royal flush"
+		}
+		return fmt.Sprintf("%s-high straight flush", rankNames[tb[0]][0])
+	case FourOfAKind:
+		return fmt.Sprintf("Four %s, %s", rankNames[tb[0]][1], kickerText(tb[1:]))
+	case FullHouse:
+		return fmt.Sprintf("%s full of %s", rankNames[tb[0]][1], rankNames[tb[1]][1])
+	case Flush:
+		return fmt.Sprintf("%s-high flush, %s", rankNames[tb[0]][0], kickerText(tb[1:]))
+	case Straight:
+		return fmt.Sprintf("%s-high straight", rankNames[tb[0]][0])
+	case ThreeOfAKind:
+		return fmt.Sprintf("Three %s, %s", rankNames[tb[0]][1], kickerText(tb[1:]))
+	case TwoPair:
+		return fmt.Sprintf("%s and %s, %s", rankNames[tb[0]][1], rankNames[tb[1]][1], kickerText(tb[2:]))
+	case OnePair:
+		return fmt.Sprintf("Pair of %s, %s", rankNames[tb[0]][1], kickerText(tb[1:]))
+	default:
+		if s.tables().low {
+			return rankList(tb) + " low"
+		}
+		return fmt.Sprintf("%s-high, %s", rankNames[tb[0]][0], kickerText(tb[1:]))
+	}
+}
+
+// formats kicker ranks as "K-9-7 kicker".
+func kickerText(ranks []int) string {
+	return rankList(ranks) + " kicker"
+}
+
+// formats rank values as "K-9-7".
+func rankList(ranks []int) string {
+	parts := make([]string, 0, len(ranks))
+	for _, v := range ranks {
+		parts = append(parts, rankText(v))
+	}
+	return strings.Join(parts, "-")
+}
+
+// formats a rank value as its card character, with a low ace as "A".
+func rankText(v int) string {
+	if v == 1 {
+		v = 14
+	}
+	r, _ := RankToChar(v)
+	return string(r)
+}
@@ -0,0 +1,385 @@
+package poker
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Score is a single comparable hand strength. It packs the ranking rules, the
+// category and the tiebreak ranks so that comparing two scores from the same
+// rules agrees with Compare on the corresponding HandRank values.
+type Score uint32
+
+const (
+	scoreSlotShift    = 20
+	scoreRulesShift   = 24
+	scoreValueMask    = 1<<scoreRulesShift - 1
+	scoreTiebreakBits = 4
+	maxTiebreaks      = 5
+
+	numRanks      = 13
+	numCategories = 10
+	maxRankCount  = 4
+	minEvalCards  = 5
+	maxEvalCards  = 9
+)
+
+// number of tiebreak values carried by each category.
+var tiebreakLen = map[Category]int{
+	HighCard:      5,
+	OnePair:       4,
+	TwoPair:       3,
+	ThreeOfAKind:  3,
+	Straight:      1,
+	Flush:         5,
+	FullHouse:     2,
+	FourOfAKind:   2,
+	StraightFlush: 1,
+	FiveOfAKind:   1,
+}
+
+var (
+	// quinaryCount[n][k] is the number of ways to put k cards into n ranks
+	// with at most four cards per rank.
+	quinaryCount [numRanks + 1][maxEvalCards + 1]uint32
+	// quinaryStep[i][k][c] is the hash contribution of holding c cards of
+	// rank i when k cards are still unplaced from rank i upwards.
+	quinaryStep [numRanks][maxEvalCards + 1][maxRankCount + 1]uint32
+	// rankTableOffset[k] is where hands of k cards start in a rank table.
+	rankTableOffset [maxEvalCards + 1]uint32
+	// rankTableSize is the number of entries in a rank table.
+	rankTableSize uint32
+)
+
+// rankTables holds the lookup tables for one set of high-hand ranking rules.
+type rankTables struct {
+	id int
+	// order lists the categories from weakest to strongest.
+	order [numCategories]Category
+	// slot is the position of each category in order.
+	slot [numCategories]Score
+	// wheelHigh is the top card of the straight in which the ace plays low,
+	// or 0 if the ace only plays high.
+	wheelHigh int
+	// lowestRank is the lowest rank index in the deck.
+	lowestRank int
+	// maxCards is the largest hand the tables score directly.
+	maxCards int
+	// low inverts the ranking so that the weakest high hand scores best.
+	low bool
+	// aceLow ranks the ace below the deuce and ignores straights and flushes.
+	aceLow bool
+
+	// rankTable scores every multiset of card ranks ignoring suits.
+	rankTable []Score
+	// flushTable scores every set of ranks held in a single suit.
+	flushTable [1 << numRanks]Score
+
+	// classScores[i] is the score of strength class i+1.
+	classScores []Score
+	// scoreClass maps a score back to its strength class.
+	scoreClass map[Score]int
+}
+
+var (
+	// standardTables rank hands dealt from a 52-card deck.
+	standardTables = &rankTables{
+		id: 0,
+		order: [numCategories]Category{
+			HighCard, OnePair, TwoPair, ThreeOfAKind, Straight,
+			Flush, FullHouse, FourOfAKind, StraightFlush, FiveOfAKind,
+		},
+		wheelHigh:  5,
+		lowestRank: 0,
+		maxCards:   maxEvalCards,
+	}
+	// shortDeckTables rank hands dealt from the 36-card six-plus deck, where
+	// a flush beats a full house and A-6-7-8-9 is the lowest straight.
+	shortDeckTables = &rankTables{
+		id: 1,
+		order: [numCategories]Category{
+			HighCard, OnePair, TwoPair, ThreeOfAKind, Straight,
+			FullHouse, Flush, FourOfAKind, StraightFlush, FiveOfAKind,
+		},
+		wheelHigh:  9,
+		lowestRank: 4,
+		maxCards:   maxEvalCards,
+	}
+	// aceToFiveTables rank 5-card lows where the ace is the lowest card and
+	// straights and flushes do not count, so 5-4-3-2-A is the best hand.
+	aceToFiveTables = &rankTables{
+		id:       2,
+		order:    standardTables.order,
+		maxCards: 5,
+		low:      true,
+		aceLow:   true,
+	}
+	// deuceToSevenTables rank 5-card lows where the ace is always high and
+	// straights and flushes count against the hand, so 7-5-4-3-2 is the best.
+	deuceToSevenTables = &rankTables{
+		id:       3,
+		order:    standardTables.order,
+		maxCards: 5,
+		low:      true,
+	}
+
+	tablesByID = []*rankTables{standardTables, shortDeckTables, aceToFiveTables, deuceToSevenTables}
+)
+
+func init() {
+	buildQuinaryHash()
+	for _, t := range tablesByID {
+		t.build()
+	}
+	eightOrBetter = aceToFiveTables.makeScore(HighCard, 8, 7, 6, 5, 4)
+}
+
+// packs a category and its tiebreak ranks into a score.
+func (t *rankTables) makeScore(cat Category, tiebreak ...int) Score {
+	s := t.slot[cat] << scoreSlotShift
+	for i, v := range tiebreak {
+		s |= Score(v) << (scoreTiebreakBits * (maxTiebreaks - 1 - i))
+	}
+	if t.low {
+		s = scoreValueMask - s
+	}
+	return Score(t.id)<<scoreRulesShift | s
+}
+
+// returns the category and tiebreak bits of the score as a high hand.
+func (s Score) value() Score {
+	v := s & scoreValueMask
+	if s.tables().low {
+		v = scoreValueMask - v
+	}
+	return v
+}
+
+// returns the tables the score was produced with.
+func (s Score) tables() *rankTables {
+	return tablesByID[s>>scoreRulesShift]
+}
+
+// Category returns the hand category encoded in the score.
+func (s Score) Category() Category {
+	return s.tables().order[s.value()>>scoreSlotShift]
+}
+
+// Tiebreak returns the tiebreak ranks encoded in the score, highest priority first.
+func (s Score) Tiebreak() []int {
+	n := tiebreakLen[s.Category()]
+	v := s.value()
+	tb := make([]int, n)
+	for i := range tb {
+		tb[i] = int(v>>(scoreTiebreakBits*(maxTiebreaks-1-i))) & (1<<scoreTiebreakBits - 1)
+	}
+	return tb
+}
+
+// prepares the perfect hash over rank counts (a base-5 number whose digits
+// sum to the hand size) so that each hand size maps onto a dense range.
+func buildQuinaryHash() {
+	quinaryCount[0][0] = 1
+	for n := 1; n <= numRanks; n++ {
+		for k := 0; k <= maxEvalCards; k++ {
+			for c := 0; c <= maxRankCount && c <= k; c++ {
+				quinaryCount[n][k] += quinaryCount[n-1][k-c]
+			}
+		}
+	}
+	for i := 0; i < numRanks; i++ {
+		rest := numRanks - 1 - i
+		for k := 0; k <= maxEvalCards; k++ {
+			for c := 1; c <= maxRankCount; c++ {
+				quinaryStep[i][k][c] = quinaryStep[i][k][c-1]
+				if k-(c-1) >= 0 {
+					quinaryStep[i][k][c] += quinaryCount[rest][k-(c-1)]
+				}
+			}
+		}
+	}
+	for k := minEvalCards; k <= maxEvalCards; k++ {
+		rankTableOffset[k] = rankTableSize
+		rankTableSize += quinaryCount[numRanks][k]
+	}
+}
+
+// maps rank counts for a hand of n cards to its index in a rank table.
+func hashRankCounts(counts *[numRanks]uint8, n int) uint32 {
+	h := rankTableOffset[n]
+	k := n
+	for i := 0; i < numRanks; i++ {
+		c := counts[i]
+		h += quinaryStep[i][k][c]
+		k -= int(c)
+	}
+	return h
+}
+
+func (t *rankTables) build() {
+	for i, cat := range t.order {
+		t.slot[cat] = Score(i)
+	}
+	t.buildRankTable()
+	t.buildFlushTable()
+	t.buildStrengthClasses()
+}
+
+// fills rankTable by walking every rank multiset of each hand size that the
+// deck can produce.
+func (t *rankTables) buildRankTable() {
+	size := rankTableSize
+	if t.maxCards < maxEvalCards {
+		size = rankTableOffset[t.maxCards+1]
+	}
+	t.rankTable = make([]Score, size)
+	var counts [numRanks]uint8
+	var walk func(i, left, n int)
+	walk = func(i, left, n int) {
+		if i == numRanks {
+			if left == 0 {
+				if t.aceLow {
+					t.rankTable[hashRankCounts(&counts, n)] = t.scoreAceLowCounts(&counts)
+				} else {
+					t.rankTable[hashRankCounts(&counts, n)] = t.scoreRankCounts(&counts)
+				}
+			}
+			return
+		}
+		for c := 0; c <= maxRankCount && c <= left; c++ {
+			counts[i] = uint8(c)
+			walk(i+1, left-c, n)
+		}
+		counts[i] = 0
+	}
+	for n := minEvalCards; n <= t.maxCards; n++ {
+		walk(t.lowestRank, n, n)
+	}
+}
+
+// fills flushTable for every suit holding of at least five ranks.
+func (t *rankTables) buildFlushTable() {
+	if t.aceLow {
+		return
+	}
+	for mask := 0; mask < len(t.flushTable); mask++ {
+		if bits.OnesCount16(uint16(mask)) < 5 || mask&(1<<t.lowestRank-1) != 0 {
+			continue
+		}
+		if ok, high := straightHigh(uint16(mask), t.wheelHigh); ok {
+			t.flushTable[mask] = t.makeScore(StraightFlush, high)
+			continue
+		}
+		t.flushTable[mask] = t.makeScore(Flush, topRanks(uint16(mask), 5)...)
+	}
+}
+
+// scores the best five-card hand that ignores suits for the given rank counts.
+func (t *rankTables) scoreRankCounts(counts *[numRanks]uint8) Score {
+	var mask uint16
+	for i, c := range counts {
+		if c > 0 {
+			mask |= 1 << i
+		}
+	}
+	// highest rank value holding at least min cards, skipping the excluded ones.
+	highest := func(min int, exclude ...int) int {
+		for i := numRanks - 1; i >= 0; i-- {
+			if int(counts[i]) < min {
+				continue
+			}
+			skip := false
+			for _, e := range exclude {
+				if e == i+2 {
+					skip = true
+				}
+			}
+			if !skip {
+				return i + 2
+			}
+		}
+		return 0
+	}
+	// top n single ranks not in the excluded set.
+	kickers := func(n int, exclude ...int) []int {
+		m := mask
+		for _, e := range exclude {
+			m &^= 1 << (e - 2)
+		}
+		return topRanks(m, n)
+	}
+
+	if quad := highest(4); quad != 0 {
+		return t.makeScore(FourOfAKind, quad, kickers(1, quad)[0])
+	}
+	if trip := highest(3); trip != 0 {
+		if pair := highest(2, trip); pair != 0 {
+			return t.makeScore(FullHouse, trip, pair)
+		}
+	}
+	if ok, high := straightHigh(mask, t.wheelHigh); ok {
+		return t.makeScore(Straight, high)
+	}
+	if trip := highest(3); trip != 0 {
+		return t.makeScore(ThreeOfAKind, append([]int{trip}, kickers(2, trip)...)...)
+	}
+	if high := highest(2); high != 0 {
+		if low := highest(2, high); low != 0 {
+			return t.makeScore(TwoPair, high, low, kickers(1, high, low)[0])
+		}
+		return t.makeScore(OnePair, append([]int{high}, kickers(3, high)...)...)
+	}
+	return t.makeScore(HighCard, topRanks(mask, 5)...)
+}
+
+// scores a 5-card hand with the ace counted as 1 and no straights or flushes.
+func (t *rankTables) scoreAceLowCounts(counts *[numRanks]uint8) Score {
+	type group struct{ count, value int }
+	groups := make([]group, 0, 5)
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		v := i + 2
+		if i == numRanks-1 {
+			v = 1
+		}
+		groups = append(groups, group{int(c), v})
+	}
+	// pairs and trips lead the tiebreak, each block highest rank first.
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return groups[i].value > groups[j].value
+	})
+	tb := make([]int, len(groups))
+	for i, g := range groups {
+		tb[i] = g.value
+	}
+	cat := HighCard
+	switch {
+	case groups[0].count == 4:
+		cat = FourOfAKind
+	case groups[0].count == 3 && groups[1].count == 2:
+		cat = FullHouse
+	case groups[0].count == 3:
+		cat = ThreeOfAKind
+	case groups[0].count == 2 && groups[1].count == 2:
+		cat = TwoPair
+	case groups[0].count == 2:
+		cat = OnePair
+	}
+	return t.makeScore(cat, tb...)
+}
+
+// returns the n highest rank values present in mask, highest first.
+func topRanks(mask uint16, n int) []int {
+	out := make([]int, 0, n)
+	for i := numRanks - 1; i >= 0 && len(out) < n; i-- {
+		if mask&(1<<i) != 0 {
+			out = append(out, i+2)
+		}
+	}
+	return out
+}
@@ -0,0 +1,283 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Variant selects the game rules used to evaluate hands.
+type Variant int
+
+const (
+	Holdem Variant = iota
+	Omaha
+	ShortDeckHoldem
+	OmahaHiLo
+	StudHiLo
+)
+
+var variantName = map[Variant]string{
+	Holdem:          "holdem",
+	Omaha:           "omaha",
+	ShortDeckHoldem: "shortdeck",
+	OmahaHiLo:       "omaha8",
+	StudHiLo:        "stud8",
+}
+
+// rules describes how a variant deals and scores hands.
+type rules struct {
+	holeMin, holeMax int
+	holeErr          error
+	// deck is every card that can be dealt.
+	deck CardSet
+	// boardCards is the size of a complete community board (0 for stud).
+	boardCards int
+	// dealtHole is how many cards each player holds at showdown, or 0 if
+	// every player holds as many as the hero.
+	dealtHole int
+	// mustPlay is how many hole cards a hand must use, the rest coming from
+	// the board, or 0 if any cards may play.
+	mustPlay int
+	// score returns the best score for hole cards on a complete board.
+	score func(hole, board []Card) (Score, error)
+	// evaluate is score plus the five cards that make the hand.
+	evaluate func(hole, board []Card) (HandRank, error)
+	// low returns the best qualifying low score, or 0 if there is none;
+	// nil for games that award the whole pot to the high hand.
+	low func(hole, board []Card) (Score, error)
+	// evaluateLow is low plus the five cards that make the hand.
+	evaluateLow func(hole, board []Card) (HandRank, bool, error)
+}
+
+var variantRules = map[Variant]rules{
+	Holdem: {
+		holeMin:    2,
+		holeMax:    2,
+		holeErr:    errors.New("hole must have 2 cards"),
+		deck:       FullDeck,
+		boardCards: 5,
+		score:      scoreHoldem,
+		evaluate:   evaluateHoldem,
+	},
+	Omaha: {
+		holeMin:    omahaMinHole,
+		holeMax:    omahaMaxHole,
+		holeErr:    errors.New("hole must have 4 to 6 cards"),
+		deck:       FullDeck,
+		boardCards: 5,
+		mustPlay:   2,
+		score:      ScoreOmaha,
+		evaluate:   EvaluateOmaha,
+	},
+	ShortDeckHoldem: {
+		holeMin:    2,
+		holeMax:    2,
+		holeErr:    errors.New("hole must have 2 cards"),
+		deck:       ShortDeck,
+		boardCards: 5,
+		score:      scoreShortDeckHoldem,
+		evaluate:   evaluateShortDeckHoldem,
+	},
+	OmahaHiLo: {
+		holeMin:     omahaMinHole,
+		holeMax:     omahaMaxHole,
+		holeErr:     errors.New("hole must have 4 to 6 cards"),
+		deck:        FullDeck,
+		boardCards:  5,
+		mustPlay:    2,
+		score:       ScoreOmaha,
+		evaluate:    EvaluateOmaha,
+		low:         scoreOmahaLow,
+		evaluateLow: evaluateOmahaLow,
+	},
+	StudHiLo: {
+		holeMin:     3,
+		holeMax:     7,
+		holeErr:     errors.New("hole must have 3 to 7 cards"),
+		deck:        FullDeck,
+		dealtHole:   7,
+		score:       scoreStud,
+		evaluate:    evaluateStud,
+		low:         scoreStudLow,
+		evaluateLow: evaluateStudLow,
+	},
+}
+
+func (v Variant) String() string {
+	if name, ok := variantName[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("variant(%d)", int(v))
+}
+
+// ParseVariant maps a variant name to a Variant; an empty name means Hold'em.
+func ParseVariant(s string) (Variant, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return Holdem, nil
+	}
+	for v, name := range variantName {
+		if name == s {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid variant '%s'", s)
+}
+
+func (v Variant) rules() (rules, error) {
+	r, ok := variantRules[v]
+	if !ok {
+		return rules{}, fmt.Errorf("unsupported variant %s", v)
+	}
+	return r, nil
+}
+
+// checks the number of hole cards against the variant.
+func (r rules) checkHole(n int) error {
+	if n < r.holeMin || n > r.holeMax {
+		return r.holeErr
+	}
+	return nil
+}
+
+// checks the number of hole cards in a hand evaluated as it stands. Stud
+// equity deals the missing cards, but a stud hand needs at least five cards
+// to be evaluated.
+func (r rules) checkEvalHole(n int) error {
+	if r.boardCards == 0 && n < minEvalCards {
+		return fmt.Errorf("hole must have %d to %d cards to evaluate", minEvalCards, r.holeMax)
+	}
+	return r.checkHole(n)
+}
+
+// checks the size of a complete board against the variant.
+func (r rules) checkBoard(n int) error {
+	if r.boardCards == 0 && n != 0 {
+		return errors.New("community must be empty for stud games")
+	}
+	if r.boardCards != 0 && (n < 3 || n > r.boardCards) {
+		return errors.New("community must have 3, 4, or 5 cards")
+	}
+	return nil
+}
+
+// checks that the board is complete, as it must be at showdown.
+func (r rules) checkShowdownBoard(n int) error {
+	if err := r.checkBoard(n); err != nil {
+		return err
+	}
+	if n != r.boardCards {
+		return fmt.Errorf("community must have %d cards at showdown", r.boardCards)
+	}
+	return nil
+}
+
+// checks that a hand is complete, as it must be at showdown: stud hands hold
+// every card dealt.
+func (r rules) checkShowdownHole(n int) error {
+	if r.dealtHole != 0 && n != r.dealtHole {
+		return fmt.Errorf("hole must have %d cards at showdown", r.dealtHole)
+	}
+	return r.checkHole(n)
+}
+
+// checks that every card can be dealt from the variant's deck.
+func (r rules) checkCards(groups ...[]Card) error {
+	for _, cs := range groups {
+		if err := checkDeck(cs, r.deck); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewVariantDeck creates the deck the variant is dealt from, in a deterministic order.
+func NewVariantDeck(v Variant) ([]Card, error) {
+	r, err := v.rules()
+	if err != nil {
+		return nil, err
+	}
+	return r.deck.Cards(), nil
+}
+
+// Evaluate computes the best high hand for the given hole cards and board
+// under the variant's rules: a board of 3 to 5 cards, or none for stud.
+func Evaluate(v Variant, hole, board []Card) (HandRank, error) {
+	r, err := v.rules()
+	if err != nil {
+		return HandRank{}, err
+	}
+	if err := r.checkEvalHole(len(hole)); err != nil {
+		return HandRank{}, err
+	}
+	if err := r.checkBoard(len(board)); err != nil {
+		return HandRank{}, err
+	}
+	if err := r.checkCards(hole, board); err != nil {
+		return HandRank{}, err
+	}
+	return r.evaluate(hole, board)
+}
+
+// scores a complete hand for the high pot and, in hi-lo games, the low pot.
+func (r rules) showdown(hole, board []Card) (high, low Score, err error) {
+	high, err = r.score(hole, board)
+	if err != nil || r.low == nil {
+		return high, 0, err
+	}
+	low, err = r.low(hole, board)
+	return high, low, err
+}
+
+// HiLo reports whether the variant splits the pot with a qualifying low.
+func (v Variant) HiLo() bool {
+	r, err := v.rules()
+	return err == nil && r.low != nil
+}
+
+// EvaluateLow computes the best eight-or-better low for a hi-lo variant.
+// ok is false when the hand has no qualifying low.
+func EvaluateLow(v Variant, hole, board []Card) (rank HandRank, ok bool, err error) {
+	r, err := v.rules()
+	if err != nil {
+		return HandRank{}, false, err
+	}
+	if r.evaluateLow == nil {
+		return HandRank{}, false, fmt.Errorf("variant %s has no low hand", v)
+	}
+	if err := r.checkEvalHole(len(hole)); err != nil {
+		return HandRank{}, false, err
+	}
+	if err := r.checkBoard(len(board)); err != nil {
+		return HandRank{}, false, err
+	}
+	if err := r.checkCards(hole, board); err != nil {
+		return HandRank{}, false, err
+	}
+	return r.evaluateLow(hole, board)
+}
+
+func scoreHoldem(hole, board []Card) (Score, error) {
+	var buf [7]Card
+	n := copy(buf[:], hole)
+	n += copy(buf[n:], board)
+	return scoreCards(buf[:n])
+}
+
+func evaluateHoldem(hole, board []Card) (HandRank, error) {
+	all := append(append([]Card{}, hole...), board...)
+	return EvaluateN(all)
+}
+
+func scoreShortDeckHoldem(hole, board []Card) (Score, error) {
+	var buf [7]Card
+	n := copy(buf[:], hole)
+	n += copy(buf[n:], board)
+	return shortDeckTables.score(buf[:n])
+}
+
+func evaluateShortDeckHoldem(hole, board []Card) (HandRank, error) {
+	all := append(append([]Card{}, hole...), board...)
+	return EvaluateShortDeck(all)
+}
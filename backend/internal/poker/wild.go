@@ -0,0 +1,149 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxWildSearch is the most wild cards whose substitutions are searched; with
+// more wild cards the hand is always at least five of a kind.
+const maxWildSearch = 3
+
+// WildRank returns the four cards of the given rank character, for games
+// such as "deuces wild".
+func WildRank(rank byte) (CardSet, error) {
+	if rankIndex[rank] < 0 {
+		return 0, fmt.Errorf("invalid rank '%c'", rank)
+	}
+	var s CardSet
+	for _, c := range NewDeck() {
+		if c.Rank() == rank {
+			s = s.Add(c)
+		}
+	}
+	return s, nil
+}
+
+// EvaluateWild computes the best 5-card hand from 5 to 9 cards where the
+// joker and every card in wild may stand for any card. A wild card only
+// duplicates a card already held when that makes five of a kind, which ranks
+// above a straight flush. PlayedAs reports what each card in Best5 plays as.
+func EvaluateWild(cs []Card, wild CardSet) (HandRank, error) {
+	if len(cs) < minEvalCards || len(cs) > maxEvalCards {
+		return HandRank{}, errHandSize
+	}
+	wild = wild.Add(Joker)
+	naturals := make([]Card, 0, len(cs))
+	wilds := make([]Card, 0, len(cs))
+	for _, c := range cs {
+		switch {
+		case wild.Contains(c):
+			wilds = append(wilds, c)
+		case int(c) < numCards:
+			naturals = append(naturals, c)
+		default:
+			return HandRank{}, errInvalidCard
+		}
+	}
+	if len(wilds) == 0 {
+		return EvaluateN(cs)
+	}
+	if rank, ok := fiveOfAKind(naturals, wilds); ok {
+		return rank, nil
+	}
+	if len(wilds) > maxWildSearch {
+		return HandRank{}, errors.New("too many wild cards")
+	}
+
+	// try every set of distinct substitutes the hand does not already hold
+	held := NewCardSet(naturals...)
+	candidates := FullDeck.Difference(held).Cards()
+	hand := append(append([]Card{}, naturals...), wilds...)
+	subs := hand[len(naturals):]
+	best := make([]Card, len(wilds))
+	var bestScore Score
+	var search func(start, n int) error
+	search = func(start, n int) error {
+		if n == len(subs) {
+			s, err := standardTables.score(hand)
+			if err != nil {
+				return err
+			}
+			if s > bestScore {
+				bestScore = s
+				copy(best, subs)
+			}
+			return nil
+		}
+		for i := start; i < len(candidates); i++ {
+			subs[n] = candidates[i]
+			if err := search(i+1, n+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := search(0, 0); err != nil {
+		return HandRank{}, err
+	}
+
+	// pick the five cards from the substituted hand and map them back
+	copy(subs, best)
+	playedAs := bestFive(hand, bestScore)
+	played := map[Card]Card{}
+	for i, c := range best {
+		played[c] = wilds[i]
+	}
+	best5 := make([]Card, len(playedAs))
+	for i, c := range playedAs {
+		best5[i] = c
+		if w, ok := played[c]; ok {
+			best5[i] = w
+		}
+	}
+	rank := newHandRank(bestScore, best5)
+	rank.PlayedAs = playedAs
+	return rank, nil
+}
+
+// returns five of a kind in the highest rank that the naturals and wild
+// cards can make, using a wild card for each missing card.
+func fiveOfAKind(naturals, wilds []Card) (HandRank, bool) {
+	var counts [numRanks]int
+	for _, c := range naturals {
+		counts[c.rankIndex()]++
+	}
+	for r := numRanks - 1; r >= 0; r-- {
+		if counts[r]+len(wilds) < 5 {
+			continue
+		}
+		best5 := make([]Card, 0, 5)
+		playedAs := make([]Card, 0, 5)
+		for _, c := range naturals {
+			if c.rankIndex() == r {
+				best5 = append(best5, c)
+				playedAs = append(playedAs, c)
+			}
+		}
+		// wild cards play as the missing rank in the suits not yet held,
+		// spades first; only the fifth card has to repeat a suit, as spades.
+		held := NewCardSet(playedAs...)
+		suit := numSuits - 1
+		for _, w := range wilds[:5-len(best5)] {
+			c := Card(numSuits*numRanks - numRanks + r)
+			for ; suit >= 0; suit-- {
+				if next := Card(suit*numRanks + r); !held.Contains(next) {
+					c = next
+					suit--
+					break
+				}
+			}
+			best5 = append(best5, w)
+			playedAs = append(playedAs, c)
+		}
+		rank := newHandRank(standardTables.makeScore(FiveOfAKind, r+2), best5)
+		rank.PlayedAs = playedAs
+		return rank, true
+	}
+	return HandRank{}, false
+}